@@ -0,0 +1,264 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器打开时快速失败的错误
+var ErrCircuitOpen = errors.New("stream: circuit breaker is open")
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 关闭，正常调用
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 打开，所有调用快速失败
+	BreakerOpen
+	// BreakerHalfOpen 半开，冷却时间结束后允许少量试探调用，成功则关闭，失败则重新打开
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerConfig 熔断器配置，MaxConsecutiveFailures 和 FailureRate 至少设置一个
+type BreakerConfig struct {
+	// MaxConsecutiveFailures 连续失败达到该次数时打开，0 表示不按连续失败次数判断
+	MaxConsecutiveFailures int
+	// FailureRate 时间窗口 Window 内失败率达到该值(0,1]时打开，0 表示不按失败率判断
+	FailureRate float64
+	// Window 统计失败率的滑动时间窗口
+	Window time.Duration
+	// MinCalls 窗口内的调用次数达到该值之后才按失败率判断，避免调用很少时误判
+	MinCalls int
+	// Cooldown 打开之后经过该时间进入半开状态
+	Cooldown time.Duration
+	// HalfOpenCalls 半开状态下允许同时进行的试探调用次数，默认为 1
+	HalfOpenCalls int
+	// OnStateChange 状态变化时调用，可以用来记录日志
+	OnStateChange func(from, to BreakerState)
+	// Clock 使用的时钟，为 nil 时使用 SystemClock
+	Clock Clock
+}
+
+// breakerBuckets 滑动窗口划分的桶数
+const breakerBuckets = 10
+
+type breakerBucket struct {
+	start    time.Time
+	calls    int
+	failures int
+}
+
+/*
+CircuitBreaker 熔断器，可以在多个流(以及长期运行的 OfFrom 流)之间共享，并发安全，配合 MapWithBreaker 使用
+
+eg:
+
+	breaker := NewCircuitBreaker(BreakerConfig{
+		MaxConsecutiveFailures: 5,
+		Cooldown:               10 * time.Second,
+		OnStateChange: func(from, to BreakerState) {
+			log.Printf("breaker %s -> %s", from, to)
+		},
+	})
+*/
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	cfg         BreakerConfig
+	state       BreakerState
+	consecutive int
+	buckets     [breakerBuckets]breakerBucket
+	openedAt    time.Time
+	inFlight    int
+	// generation 每次状态变化时加 1，用来识别之前的状态下开始的调用
+	generation uint64
+}
+
+// BreakerToken Allow 允许的一次调用，调用结束后通过 Done 报告结果
+type BreakerToken struct {
+	generation uint64
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.MaxConsecutiveFailures <= 0 && cfg.FailureRate <= 0 {
+		panic("MaxConsecutiveFailures or FailureRate must be set")
+	}
+	if cfg.FailureRate > 0 && cfg.Window <= 0 {
+		panic("Window must be positive when FailureRate is set")
+	}
+	if cfg.HalfOpenCalls <= 0 {
+		cfg.HalfOpenCalls = 1
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+	return &CircuitBreaker{cfg: cfg}
+}
+
+// State 返回当前状态
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Allow 判断是否允许一次调用，允许时调用结束后必须通过 Done 报告结果
+func (cb *CircuitBreaker) Allow() (BreakerToken, bool) {
+	cb.mu.Lock()
+	from := cb.state
+	allowed := false
+	switch cb.state {
+	case BreakerClosed:
+		allowed = true
+	case BreakerOpen:
+		if cb.cfg.Clock.Now().Sub(cb.openedAt) >= cb.cfg.Cooldown {
+			cb.state = BreakerHalfOpen
+			cb.generation++
+			cb.inFlight = 1
+			allowed = true
+		}
+	case BreakerHalfOpen:
+		if cb.inFlight < cb.cfg.HalfOpenCalls {
+			cb.inFlight++
+			allowed = true
+		}
+	}
+	to := cb.state
+	token := BreakerToken{generation: cb.generation}
+	cb.mu.Unlock()
+	cb.notify(from, to)
+	return token, allowed
+}
+
+// Done 报告 token 对应的调用的结果，err 为 nil 表示成功
+// 在之前的状态下开始的调用(比如关闭时开始、半开时才结束的调用)的结果会被忽略，不影响当前状态
+func (cb *CircuitBreaker) Done(token BreakerToken, err error) {
+	cb.mu.Lock()
+	if token.generation != cb.generation {
+		cb.mu.Unlock()
+		return
+	}
+	from := cb.state
+	now := cb.cfg.Clock.Now()
+	switch cb.state {
+	case BreakerHalfOpen:
+		cb.inFlight--
+		if err != nil {
+			cb.open(now)
+		} else {
+			cb.reset()
+		}
+	case BreakerClosed:
+		cb.record(now, err != nil)
+		if cb.shouldOpen(now) {
+			cb.open(now)
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(from, to)
+}
+
+func (cb *CircuitBreaker) notify(from, to BreakerState) {
+	if from != to && cb.cfg.OnStateChange != nil {
+		cb.cfg.OnStateChange(from, to)
+	}
+}
+
+func (cb *CircuitBreaker) open(now time.Time) {
+	cb.state = BreakerOpen
+	cb.generation++
+	cb.openedAt = now
+	cb.inFlight = 0
+}
+
+func (cb *CircuitBreaker) reset() {
+	cb.state = BreakerClosed
+	cb.generation++
+	cb.consecutive = 0
+	cb.buckets = [breakerBuckets]breakerBucket{}
+}
+
+func (cb *CircuitBreaker) record(now time.Time, failed bool) {
+	if failed {
+		cb.consecutive++
+	} else {
+		cb.consecutive = 0
+	}
+	if cb.cfg.FailureRate <= 0 {
+		return
+	}
+	width := cb.cfg.Window / breakerBuckets
+	if width <= 0 {
+		width = 1
+	}
+	start := now.Truncate(width)
+	b := &cb.buckets[(start.UnixNano()/int64(width))%breakerBuckets]
+	if !b.start.Equal(start) {
+		*b = breakerBucket{start: start}
+	}
+	b.calls++
+	if failed {
+		b.failures++
+	}
+}
+
+func (cb *CircuitBreaker) shouldOpen(now time.Time) bool {
+	if cb.cfg.MaxConsecutiveFailures > 0 && cb.consecutive >= cb.cfg.MaxConsecutiveFailures {
+		return true
+	}
+	if cb.cfg.FailureRate <= 0 {
+		return false
+	}
+	var calls, failures int
+	for _, b := range cb.buckets {
+		if now.Sub(b.start) < cb.cfg.Window {
+			calls += b.calls
+			failures += b.failures
+		}
+	}
+	return calls > 0 && calls >= cb.cfg.MinCalls && float64(failures)/float64(calls) >= cb.cfg.FailureRate
+}
+
+/*
+MapWithBreaker 通过熔断器调用 fn 进行转换
+fn 返回错误，或者熔断器打开时(错误为 ErrCircuitOpen)，元素交给 fallback 处理，fallback 返回 true 时输出它的返回值
+fallback 为 nil 时失败的元素被丢弃，第一个错误可以在终止操作之后通过 Err() 获取
+*/
+func MapWithBreaker[T any, R any](s Stream[T], breaker *CircuitBreaker, fn func(T) (R, error),
+	fallback func(item T, err error) (R, bool)) Stream[R] {
+	ctl := newControl()
+	return walk(s, func(item T, pipe chan<- R) {
+		var r R
+		err := ErrCircuitOpen
+		if token, ok := breaker.Allow(); ok {
+			r, err = safeCallE(item, fn)
+			breaker.Done(token, err)
+		}
+		if err == nil {
+			pipe <- r
+			return
+		}
+		if fallback == nil {
+			ctl.fail(err)
+			return
+		}
+		if r, ok := fallback(item, err); ok {
+			pipe <- r
+		}
+	}).linked(ctl)
+}
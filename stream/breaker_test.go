@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerConsecutive(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var transitions []string
+	breaker := NewCircuitBreaker(BreakerConfig{
+		MaxConsecutiveFailures: 2,
+		Cooldown:               time.Second,
+		Clock:                  clock,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+		},
+	})
+	errDown := errors.New("down")
+	down := true
+	call := func(item int) (string, error) {
+		if down {
+			return "", errDown
+		}
+		return fmt.Sprint(item), nil
+	}
+	fallback := func(item int, err error) (string, bool) {
+		if errors.Is(err, ErrCircuitOpen) {
+			return fmt.Sprintf("open:%d", item), true
+		}
+		return fmt.Sprintf("fail:%d", item), true
+	}
+
+	res := MapWithBreaker(Of(1, 2, 3), breaker, call, fallback).ToSlice()
+	if want := []string{"fail:1", "fail:2", "open:3"}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MapWithBreaker() = %v, want %v", res, want)
+	}
+
+	// 冷却结束后半开，试探调用失败重新打开
+	clock.Advance(time.Second)
+	res = MapWithBreaker(Of(4, 5), breaker, call, fallback).ToSlice()
+	if want := []string{"fail:4", "open:5"}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MapWithBreaker() = %v, want %v", res, want)
+	}
+
+	// 试探调用成功后关闭
+	clock.Advance(time.Second)
+	down = false
+	res = MapWithBreaker(Of(6, 7), breaker, call, fallback).ToSlice()
+	if want := []string{"6", "7"}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MapWithBreaker() = %v, want %v", res, want)
+	}
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	breaker := NewCircuitBreaker(BreakerConfig{
+		FailureRate: 0.6,
+		Window:      10 * time.Second,
+		MinCalls:    4,
+		Cooldown:    time.Second,
+		Clock:       clock,
+	})
+	for _, failed := range []bool{true, false, true} {
+		token, _ := breaker.Allow()
+		breaker.Done(token, map[bool]error{true: errors.New("x")}[failed])
+	}
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("state = %s, want closed before MinCalls", breaker.State())
+	}
+	token, _ := breaker.Allow()
+	breaker.Done(token, nil)
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("state = %s, want closed at 2/4 failures", breaker.State())
+	}
+	token, _ = breaker.Allow()
+	breaker.Done(token, errors.New("x"))
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open at 3/5 failures", breaker.State())
+	}
+
+	s := MapWithBreaker(Of(1), breaker, func(item int) (int, error) {
+		return item, nil
+	}, nil)
+	if res := s.ToSlice(); len(res) != 0 || !errors.Is(s.Err(), ErrCircuitOpen) {
+		t.Errorf("MapWithBreaker() = %v, Err() = %v", res, s.Err())
+	}
+}
+
+func TestCircuitBreakerStaleDone(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	breaker := NewCircuitBreaker(BreakerConfig{
+		MaxConsecutiveFailures: 1,
+		Cooldown:               time.Second,
+		Clock:                  clock,
+	})
+	// 关闭时开始、很慢的调用
+	slow, _ := breaker.Allow()
+	failed, _ := breaker.Allow()
+	breaker.Done(failed, errors.New("x"))
+	clock.Advance(time.Second)
+	probe, ok := breaker.Allow()
+	if !ok || breaker.State() != BreakerHalfOpen {
+		t.Fatalf("state = %s, want half-open probe", breaker.State())
+	}
+
+	// 关闭时开始的调用在半开时结束，不能决定状态，也不能占用试探调用的名额
+	breaker.Done(slow, nil)
+	if breaker.State() != BreakerHalfOpen {
+		t.Fatalf("state = %s after stale Done, want half-open", breaker.State())
+	}
+	if _, ok := breaker.Allow(); ok {
+		t.Fatal("stale Done released the only half-open call")
+	}
+	breaker.Done(probe, errors.New("x"))
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open after failed probe", breaker.State())
+	}
+}
@@ -0,0 +1,149 @@
+package stream
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrBufferOverflow Buffer 使用 OverflowError 策略时缓冲区满的错误
+var ErrBufferOverflow = errors.New("stream: buffer overflow")
+
+// OverflowStrategy 缓冲区满时的处理策略
+type OverflowStrategy int
+
+const (
+	// OverflowBlock 阻塞上游，直到下游消费(默认的行为)
+	OverflowBlock OverflowStrategy = iota
+	// OverflowDropNewest 丢弃新到达的元素
+	OverflowDropNewest
+	// OverflowDropOldest 丢弃缓冲区中最早的元素，再放入新到达的元素
+	OverflowDropOldest
+	// OverflowError 停止读取上游，输出缓冲区中剩余的元素后结束，Err() 返回 ErrBufferOverflow
+	OverflowError
+)
+
+// BufferStats Buffer 的计数，可以在流运行过程中并发读取
+type BufferStats struct {
+	received atomic.Int64
+	emitted  atomic.Int64
+	dropped  atomic.Int64
+}
+
+// Received 从上游收到的元素个数
+func (s *BufferStats) Received() int64 {
+	return s.received.Load()
+}
+
+// Emitted 输出给下游的元素个数
+func (s *BufferStats) Emitted() int64 {
+	return s.emitted.Load()
+}
+
+// Dropped 因为缓冲区满被丢弃的元素个数
+func (s *BufferStats) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// BufferOption 设置 Buffer 的选项
+type BufferOption[T any] func(*bufferOptions[T])
+
+type bufferOptions[T any] struct {
+	onOverflow func(item T)
+	stats      *BufferStats
+}
+
+// OnOverflow 元素因为缓冲区满被丢弃时调用(OverflowError 策略下为导致溢出的元素)
+func OnOverflow[T any](fn func(item T)) BufferOption[T] {
+	return func(o *bufferOptions[T]) {
+		o.onOverflow = fn
+	}
+}
+
+// WithBufferStats 把 Buffer 的计数记录到 stats 中
+func WithBufferStats[T any](stats *BufferStats) BufferOption[T] {
+	return func(o *bufferOptions[T]) {
+		o.stats = stats
+	}
+}
+
+/*
+Buffer 在上下游之间加一个大小为 size 的缓冲区，上游不再需要等待下游逐个消费
+缓冲区满时按 strategy 处理，对于实时性要求高的场景可以选择丢弃元素而不是阻塞上游
+
+eg:
+
+	var stats stream.BufferStats
+	OfFrom(consume).Buffer(1024, stream.OverflowDropOldest, stream.WithBufferStats[Event](&stats)).ForEach(handle)
+	fmt.Println(stats.Dropped())
+*/
+func (s Stream[T]) Buffer(size int, strategy OverflowStrategy, opts ...BufferOption[T]) Stream[T] {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	o := bufferOptions[T]{stats: &BufferStats{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	source := make(chan T)
+	res := Range(source, s.isParallel).linked(s.ctl)
+	overflow := func(item T) {
+		o.stats.dropped.Add(1)
+		if o.onOverflow != nil {
+			o.onOverflow(item)
+		}
+	}
+	GoSafe(func() {
+		defer close(source)
+
+		// buf 为环形缓冲区，head 为最早的元素，n 为元素个数
+		buf := make([]T, size)
+		head, n := 0, 0
+		in := s.source
+		for in != nil || n > 0 {
+			var out chan<- T
+			var first T
+			if n > 0 {
+				out = source
+				first = buf[head]
+			}
+			read := in
+			if strategy == OverflowBlock && n == size {
+				read = nil
+			}
+			select {
+			case item, ok := <-read:
+				if !ok {
+					in = nil
+					continue
+				}
+				o.stats.received.Add(1)
+				if n < size {
+					buf[(head+n)%size] = item
+					n++
+					continue
+				}
+				switch strategy {
+				case OverflowDropNewest:
+					overflow(item)
+				case OverflowDropOldest:
+					overflow(buf[head])
+					buf[head] = item
+					head = (head + 1) % size
+				case OverflowError:
+					overflow(item)
+					res.ctl.fail(ErrBufferOverflow)
+					s.ctl.cancel()
+					go drain(in)
+					in = nil
+				}
+			case out <- first:
+				o.stats.emitted.Add(1)
+				var zero T
+				buf[head] = zero
+				head = (head + 1) % size
+				n--
+			}
+		}
+	})
+	return res
+}
@@ -0,0 +1,51 @@
+package stream
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	res := Of(1, 2, 3, 4, 5).Buffer(2, OverflowBlock).ToSlice()
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(res, want) {
+		t.Errorf("Buffer(OverflowBlock) = %v, want %v", res, want)
+	}
+
+	tests := []struct {
+		strategy OverflowStrategy
+		want     []int
+		dropped  []int
+		err      error
+	}{
+		{OverflowDropNewest, []int{1, 2}, []int{3, 4}, nil},
+		{OverflowDropOldest, []int{3, 4}, []int{1, 2}, nil},
+		{OverflowError, []int{1, 2}, []int{3}, ErrBufferOverflow},
+	}
+	for _, tt := range tests {
+		in := make(chan int)
+		var stats BufferStats
+		var dropped []int
+		s := Range(in, false).Buffer(2, tt.strategy, WithBufferStats[int](&stats), OnOverflow(func(item int) {
+			dropped = append(dropped, item)
+		}))
+		// 下游还没有开始消费，缓冲区满之后按策略处理
+		for _, item := range []int{1, 2, 3, 4} {
+			in <- item
+			if tt.strategy == OverflowError && item == 3 {
+				break
+			}
+		}
+		close(in)
+		res := s.ToSlice()
+		if !reflect.DeepEqual(res, tt.want) {
+			t.Errorf("Buffer(%d) = %v, want %v", tt.strategy, res, tt.want)
+		}
+		if !reflect.DeepEqual(dropped, tt.dropped) || stats.Dropped() != int64(len(tt.dropped)) {
+			t.Errorf("Buffer(%d) dropped = %v (%d), want %v", tt.strategy, dropped, stats.Dropped(), tt.dropped)
+		}
+		if !errors.Is(s.Err(), tt.err) {
+			t.Errorf("Buffer(%d) Err() = %v, want %v", tt.strategy, s.Err(), tt.err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package stream
+
+import (
+	"encoding/gob"
+	"os"
+	"sync"
+)
+
+// CacheOption 设置 Cache 的选项
+type CacheOption func(*cacheOptions)
+
+type cacheOptions struct {
+	spillThreshold int
+	spillDir       string
+}
+
+// WithSpillToDisk 内存中缓存的元素超过 threshold 个之后，后续元素通过 encoding/gob 写入 dir 下的临时文件
+// dir 为空时使用 os.TempDir()，元素类型需要能被 gob 编码(导出的字段)
+func WithSpillToDisk(threshold int, dir string) CacheOption {
+	return func(o *cacheOptions) {
+		if threshold < 0 {
+			panic("threshold must not be negative")
+		}
+		o.spillThreshold = threshold
+		o.spillDir = dir
+	}
+}
+
+/*
+CachedStream 可以被重复消费的流，源流中的元素在第一次消费时被缓存下来，之后每次调用 Stream() 都会从头重放
+多个 Stream() 可以同时消费，还未读取到的元素会等待源流产生
+
+eg:
+
+	cached := stream.Of(1, 2, 3).Filter(func(item int) bool {
+		return item > 1
+	}).Cache()
+	defer cached.Close()
+	count := cached.Stream().Count()
+	items := cached.Stream().ToSlice()
+*/
+type CachedStream[T any] struct {
+	source     Stream[T]
+	opts       cacheOptions
+	fillOnce   sync.Once
+	mu         sync.Mutex
+	cond       *sync.Cond
+	items      []T
+	spilled    int
+	spillFile  *os.File
+	spillCoder *gob.Encoder
+	done       bool
+	err        error
+}
+
+// Cache 把流转换为可以重复消费的 CachedStream，源流只会被消费一次
+func (s Stream[T]) Cache(opts ...CacheOption) *CachedStream[T] {
+	s.checkConsumed()
+	c := &CachedStream[T]{source: s}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Stream 返回一个从头开始重放缓存元素的新流
+func (c *CachedStream[T]) Stream() Stream[T] {
+	c.fillOnce.Do(func() {
+		GoSafe(c.fill)
+	})
+	source := make(chan T)
+	// 重放的流提前结束时只停止自己的重放，不能取消被所有重放共享的源流
+	res := Range(source, c.source.isParallel).errLinked(c.source.ctl)
+	GoSafe(func() {
+		defer close(source)
+		c.replay(source, res.ctl.Done())
+	})
+	return res
+}
+
+// Err 返回读写溢出文件时发生的错误，写入出错后缓存只包含出错之前的元素
+func (c *CachedStream[T]) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close 删除溢出文件，Close 之后不能再调用 Stream()
+func (c *CachedStream[T]) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.spillFile == nil {
+		return nil
+	}
+	name := c.spillFile.Name()
+	err := c.spillFile.Close()
+	c.spillFile = nil
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (c *CachedStream[T]) fill() {
+	defer func() {
+		c.mu.Lock()
+		c.done = true
+		c.mu.Unlock()
+		c.cond.Broadcast()
+	}()
+	for item := range c.source.source {
+		if err := c.append(item); err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			go drain(c.source.source)
+			return
+		}
+		c.cond.Broadcast()
+	}
+}
+
+func (c *CachedStream[T]) append(item T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.opts.spillThreshold == 0 || len(c.items) < c.opts.spillThreshold {
+		c.items = append(c.items, item)
+		return nil
+	}
+	if c.spillFile == nil {
+		f, err := os.CreateTemp(c.opts.spillDir, "go-stream-cache-*")
+		if err != nil {
+			return err
+		}
+		c.spillFile = f
+		c.spillCoder = gob.NewEncoder(f)
+	}
+	// 每个元素完整写入文件之后才计数，读取方只会读取已经写完的元素
+	if err := c.spillCoder.Encode(&item); err != nil {
+		return err
+	}
+	c.spilled++
+	return nil
+}
+
+// replay 依次输出内存中的元素和溢出文件中的元素，未产生的元素等待 fill，done 关闭时结束
+func (c *CachedStream[T]) replay(pipe chan<- T, done <-chan struct{}) {
+	var decoder *gob.Decoder
+	for i := 0; ; i++ {
+		c.mu.Lock()
+		for i >= len(c.items)+c.spilled && !c.done {
+			c.cond.Wait()
+		}
+		if i >= len(c.items)+c.spilled {
+			c.mu.Unlock()
+			return
+		}
+		if i < len(c.items) {
+			item := c.items[i]
+			c.mu.Unlock()
+			select {
+			case pipe <- item:
+			case <-done:
+				return
+			}
+			continue
+		}
+		name := c.spillFile.Name()
+		c.mu.Unlock()
+
+		if decoder == nil {
+			f, err := os.Open(name)
+			if err != nil {
+				c.setErr(err)
+				return
+			}
+			defer f.Close()
+			decoder = gob.NewDecoder(f)
+		}
+		var item T
+		if err := decoder.Decode(&item); err != nil {
+			c.setErr(err)
+			return
+		}
+		select {
+		case pipe <- item:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (c *CachedStream[T]) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
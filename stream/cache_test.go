@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type cacheItem struct {
+	Num   int
+	Value string
+}
+
+func TestCache(t *testing.T) {
+	cached := Of(1, 2, 3, 4).Filter(func(item int) bool {
+		return item%2 == 0
+	}).Cache()
+	defer cached.Close()
+
+	if count := cached.Stream().Count(); count != 2 {
+		t.Errorf("Count() = %d, want 2", count)
+	}
+	if res := cached.Stream().ToSlice(); !reflect.DeepEqual(res, []int{2, 4}) {
+		t.Errorf("ToSlice() = %v, want [2 4]", res)
+	}
+}
+
+func TestCacheSpillToDisk(t *testing.T) {
+	items := make([]cacheItem, 0, 100)
+	for i := 0; i < 100; i++ {
+		items = append(items, cacheItem{Num: i, Value: strings.Repeat("x", i)})
+	}
+	cached := Of(items...).Cache(WithSpillToDisk(10, t.TempDir()))
+	defer cached.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res := cached.Stream().ToSlice(); !reflect.DeepEqual(res, items) {
+				t.Errorf("replay got %d items, want %d", len(res), len(items))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := cached.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReuseCheck(t *testing.T) {
+	var reuseErr *ReuseError
+	EnableReuseCheck(func(err *ReuseError) {
+		reuseErr = err
+	})
+	defer DisableReuseCheck()
+
+	s := Of(1, 2, 3)
+	s.Count()
+	s.ToSlice()
+	if reuseErr == nil {
+		t.Fatal("expected reuse error")
+	}
+	if !strings.Contains(reuseErr.Error(), "cache_test.go") {
+		t.Errorf("error should contain the call site: %v", reuseErr)
+	}
+
+	reuseErr = nil
+	cached := Of(1, 2, 3).Cache()
+	cached.Stream().Count()
+	cached.Stream().Count()
+	if reuseErr != nil {
+		t.Errorf("replaying a cached stream should not be reported: %v", reuseErr)
+	}
+}
+
+// slowSource 每个元素之间稍作停顿，下游短路时上游还没有生产完
+func slowSource(n int) Stream[int] {
+	return OfFromE(func(emit func(int) bool) error {
+		for i := 0; i < n; i++ {
+			time.Sleep(time.Millisecond)
+			if !emit(i) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+func TestCacheShortCircuitReplay(t *testing.T) {
+	cached := slowSource(50).Cache()
+	defer cached.Close()
+
+	if res := cached.Stream().Limit(2).ToSlice(); !reflect.DeepEqual(res, []int{0, 1}) {
+		t.Fatalf("Limit(2) = %v", res)
+	}
+	// 提前结束的重放不能截断源流
+	if n := len(cached.Stream().ToSlice()); n != 50 {
+		t.Fatalf("full replay got %d items, want 50", n)
+	}
+}
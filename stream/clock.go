@@ -0,0 +1,214 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间相关操作使用的时钟，测试时可以替换为 FakeClock，不需要真正的等待
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	// NewTimer 创建一个可以停止和重置的定时器，需要反复等待时应当复用同一个 ClockTimer 而不是每次调用 After
+	NewTimer(d time.Duration) ClockTimer
+}
+
+// ClockTimer Clock 创建的定时器，同 time.Timer
+type ClockTimer interface {
+	// C 到期时发送当前时间的 channel
+	C() <-chan time.Time
+	// Stop 停止定时器，定时器还未到期时返回 true
+	Stop() bool
+	// Reset 停止定时器并丢弃还未被读取的到期时间，然后重新在 d 之后到期，定时器还未到期时返回 true
+	Reset(d time.Duration) bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) ClockTimer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+	active := t.t.Stop()
+	if !active {
+		// 丢弃已经到期但是还没有被读取的时间，否则 Reset 之后会立即收到旧的到期时间
+		select {
+		case <-t.t.C:
+		default:
+		}
+	}
+	t.t.Reset(d)
+	return active
+}
+
+// SystemClock 基于 time 包的系统时钟，是所有时间相关操作的默认时钟
+var SystemClock Clock = systemClock{}
+
+// TimeOption 设置时间相关操作的选项
+type TimeOption func(*timeOptions)
+
+type timeOptions struct {
+	clock        Clock
+	endOnTimeout bool
+}
+
+// WithClock 设置时间相关操作使用的时钟
+func WithClock(clock Clock) TimeOption {
+	return func(o *timeOptions) {
+		o.clock = clock
+	}
+}
+
+func newTimeOptions(opts []TimeOption) timeOptions {
+	o := timeOptions{clock: SystemClock}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+/*
+FakeClock 手动推进的时钟，只有调用 Advance 时时间才会前进，用于测试时间相关的操作
+
+eg:
+
+	clock := stream.NewFakeClock(time.Now())
+	s := stream.Of(1, 2, 3).RateLimit(1, 1, stream.WithClock(clock))
+	...
+	clock.BlockUntil(1) // 等待操作开始等待
+	clock.Advance(time.Second)
+*/
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFakeClock 创建一个从 now 开始的 FakeClock
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) ClockTimer {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule(t, d)
+	return t
+}
+
+// schedule 在 d 之后触发 t，d 不大于 0 时立即触发，调用时需要持有 c.mu
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	if d <= 0 {
+		t.ch <- c.now
+		return
+	}
+	t.at = c.now.Add(d)
+	c.waiters = append(c.waiters, t)
+	c.cond.Broadcast()
+}
+
+// remove 从等待列表中移除 t，返回 t 是否还未触发，调用时需要持有 c.mu
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, w := range c.waiters {
+		if w == t {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance 时间前进 d，到期的定时器会被触发
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// BlockUntil 阻塞直到至少有 n 个还未触发的定时器(包括 After)
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// waiting 返回还未触发的定时器的数量
+func (c *FakeClock) waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	ch    chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.remove(t)
+	select {
+	case <-t.ch:
+	default:
+	}
+	t.clock.schedule(t, d)
+	return active
+}
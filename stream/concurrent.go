@@ -0,0 +1,201 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConcurrentOption 设置 MapConcurrent、Paginate 的选项
+type ConcurrentOption func(*concurrentOptions)
+
+type concurrentOptions struct {
+	ctx       context.Context
+	unordered bool
+	timeout   time.Duration
+	prefetch  int
+}
+
+// WithContext 设置 fn 使用的父 context，ctx 被取消后停止处理并把 ctx.Err() 作为流的错误
+func WithContext(ctx context.Context) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithUnordered 按完成的先后顺序输出结果，默认按输入的顺序输出
+func WithUnordered() ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.unordered = true
+	}
+}
+
+// WithCallTimeout 设置每次调用 fn 的超时时间，超时后传给 fn 的 ctx 会被取消
+func WithCallTimeout(d time.Duration) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.timeout = d
+	}
+}
+
+/*
+MapConcurrent 以固定 n 个并发调用 fn 进行转换，适合 RPC 等 IO 密集的操作，不要求流是通过 OfParallel 创建的
+fn 返回错误时停止处理：取消正在进行中的调用(通过 ctx)，不再读取新的元素，错误可以在终止操作之后通过 Err() 获取
+
+eg:
+
+	res := MapConcurrent(Of(ids...), 8, func(ctx context.Context, id int) (User, error) {
+		return client.GetUser(ctx, id)
+	}, WithCallTimeout(time.Second))
+	users := res.ToSlice()
+	if err := res.Err(); err != nil {
+		...
+	}
+*/
+func MapConcurrent[T any, R any](s Stream[T], n int, fn func(ctx context.Context, item T) (R, error), opts ...ConcurrentOption) Stream[R] {
+	if n <= 0 {
+		panic("n must be positive")
+	}
+	o := concurrentOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	source := make(chan R)
+	res := Range(source, s.isParallel).linked(s.ctl)
+	ctx, cancel := context.WithCancel(o.ctx)
+	// 下游取消(比如 Limit 已经满足)时同样取消进行中的调用
+	go func() {
+		select {
+		case <-res.ctl.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	call := func(item T) (R, error) {
+		callCtx := ctx
+		if o.timeout > 0 {
+			var callCancel context.CancelFunc
+			callCtx, callCancel = context.WithTimeout(ctx, o.timeout)
+			defer callCancel()
+		}
+		return safeCall(callCtx, item, fn)
+	}
+	fail := func(err error) {
+		res.ctl.fail(err)
+		cancel()
+		s.ctl.cancel()
+	}
+	GoSafe(func() {
+		defer close(source)
+		defer cancel()
+		if o.unordered {
+			mapUnordered(ctx, s, n, call, fail, source)
+		} else {
+			mapOrdered(ctx, s, n, call, fail, source)
+		}
+		if err := o.ctx.Err(); err != nil {
+			res.ctl.fail(err)
+		}
+		go drain(s.source)
+	})
+	return res
+}
+
+func mapUnordered[T any, R any](ctx context.Context, s Stream[T], n int, call func(T) (R, error), fail func(error), out chan<- R) {
+	var wg sync.WaitGroup
+	pool := make(chan struct{}, n)
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case pool <- struct{}{}:
+		}
+		item, ok := <-s.source
+		if !ok {
+			return
+		}
+		wg.Add(1)
+		GoSafe(func() {
+			defer func() {
+				wg.Done()
+				<-pool
+			}()
+			r, err := call(item)
+			if err != nil {
+				fail(err)
+				return
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+			}
+		})
+	}
+}
+
+type concurrentResult[R any] struct {
+	value R
+	err   error
+}
+
+func mapOrdered[T any, R any](ctx context.Context, s Stream[T], n int, call func(T) (R, error), fail func(error), out chan<- R) {
+	// 每个元素一个结果槽位，槽位按输入顺序排队，队列中的 n-1 个加上正在等待的 1 个限制了并发数
+	queue := make(chan chan concurrentResult[R], n-1)
+	go func() {
+		defer close(queue)
+		for {
+			item, ok := <-s.source
+			if !ok {
+				return
+			}
+			slot := make(chan concurrentResult[R], 1)
+			select {
+			case queue <- slot:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				r, err := call(item)
+				slot <- concurrentResult[R]{value: r, err: err}
+			}()
+		}
+	}()
+	for slot := range queue {
+		var r concurrentResult[R]
+		select {
+		case r = <-slot:
+		case <-ctx.Done():
+			go drain(queue)
+			return
+		}
+		if r.err != nil {
+			fail(r.err)
+			go drain(queue)
+			return
+		}
+		select {
+		case out <- r.value:
+		case <-ctx.Done():
+			go drain(queue)
+			return
+		}
+	}
+}
+
+// safeCall 调用 fn，并把 fn 中的 panic 转换为错误
+func safeCall[T any, R any](ctx context.Context, item T, fn func(ctx context.Context, item T) (R, error)) (r R, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("stream: panic: %v", p)
+		}
+	}()
+	return fn(ctx, item)
+}
+
+// safeCallE 同 safeCall，用于不需要 context 的 fn
+func safeCallE[T any, R any](item T, fn func(item T) (R, error)) (R, error) {
+	return safeCall(context.Background(), item, func(_ context.Context, item T) (R, error) {
+		return fn(item)
+	})
+}
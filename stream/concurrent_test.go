@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapConcurrent(t *testing.T) {
+	var inFlight, maxInFlight int32
+	fn := func(ctx context.Context, item int) (int, error) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, cur) {
+				break
+			}
+		}
+		// 让前面的元素更慢，验证结果仍按输入顺序输出
+		time.Sleep(time.Duration(10-item) * time.Millisecond)
+		return item * 2, nil
+	}
+	s := MapConcurrent(Of(1, 2, 3, 4, 5, 6, 7, 8, 9), 3, fn)
+	res := s.ToSlice()
+	if want := []int{2, 4, 6, 8, 10, 12, 14, 16, 18}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MapConcurrent() = %v, want %v", res, want)
+	}
+	if s.Err() != nil {
+		t.Errorf("Err() = %v", s.Err())
+	}
+	if maxInFlight > 3 {
+		t.Errorf("max in-flight calls = %d, want <= 3", maxInFlight)
+	}
+
+	res = MapConcurrent(Of(1, 2, 3, 4, 5, 6, 7, 8, 9), 3, fn, WithUnordered()).ToSlice()
+	sort.Ints(res)
+	if want := []int{2, 4, 6, 8, 10, 12, 14, 16, 18}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MapConcurrent(unordered) = %v, want %v", res, want)
+	}
+}
+
+func TestMapConcurrentError(t *testing.T) {
+	errBoom := errors.New("boom")
+	s := MapConcurrent(Of(1, 2, 3, 4, 5), 2, func(ctx context.Context, item int) (int, error) {
+		if item == 3 {
+			return 0, errBoom
+		}
+		return item, nil
+	})
+	res := s.ToSlice()
+	if !errors.Is(s.Err(), errBoom) {
+		t.Errorf("Err() = %v, want %v", s.Err(), errBoom)
+	}
+	if !reflect.DeepEqual(res, []int{1, 2}) {
+		t.Errorf("MapConcurrent() = %v, want [1 2]", res)
+	}
+}
+
+func TestMapConcurrentTimeout(t *testing.T) {
+	s := MapConcurrent(Of(1, 2), 2, func(ctx context.Context, item int) (int, error) {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Second):
+			return item, nil
+		}
+	}, WithCallTimeout(10*time.Millisecond), WithUnordered())
+	if res := s.ToSlice(); len(res) != 0 {
+		t.Errorf("MapConcurrent() = %v, want empty", res)
+	}
+	if !errors.Is(s.Err(), context.DeadlineExceeded) {
+		t.Errorf("Err() = %v, want %v", s.Err(), context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,182 @@
+package stream
+
+import (
+	"context"
+	"sync"
+)
+
+// control 流水线中每个流的控制状态，用来向下游传递错误、向上游传递取消信号
+// 每个中间操作创建的流都持有一个新的 control，并通过 upstream 指向上游流的 control
+type control struct {
+	mu       sync.Mutex
+	err      error
+	done     chan struct{}
+	doneOnce sync.Once
+	upstream []*control
+	// errUpstream 只传递错误、不传递取消信号的上游，用于被多个消费者共享的源(比如 Cache)
+	errUpstream []*control
+	// refs 大于 0 时需要被取消 refs 次才真正取消，用于 Tee 的多个分支共享同一个上游
+	refs int
+}
+
+func newControl() *control {
+	return &control{done: make(chan struct{})}
+}
+
+// newSharedControl 被 n 个下游共享的 control，所有下游都取消之后才取消 upstream
+func newSharedControl(n int, upstream *control) *control {
+	c := newControl()
+	c.refs = n
+	c.upstream = []*control{upstream}
+	return c
+}
+
+// fail 记录当前流产生的错误，只保留第一个
+func (c *control) fail(err error) {
+	if c == nil || err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err 返回当前流或者上游流产生的第一个错误
+func (c *control) Err() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	err := c.err
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, up := range c.upstream {
+		if err := up.Err(); err != nil {
+			return err
+		}
+	}
+	for _, up := range c.errUpstream {
+		if err := up.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancel 通知当前流及其所有上游停止生产元素，下游不受影响
+func (c *control) cancel() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.refs > 0 {
+		c.refs--
+		if c.refs > 0 {
+			c.mu.Unlock()
+			return
+		}
+	}
+	c.mu.Unlock()
+	// 只向上游传递一次，重复取消不会减少共享 control 的 refs
+	c.doneOnce.Do(func() {
+		close(c.done)
+		for _, up := range c.upstream {
+			up.cancel()
+		}
+	})
+}
+
+// Done 当前流被取消时关闭
+func (c *control) Done() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.done
+}
+
+// stop 下游不再需要 s 的元素时调用，取消上游的生产并丢弃已经在路上的元素
+func (s Stream[T]) stop() {
+	s.ctl.cancel()
+	go drain(s.source)
+}
+
+// linked 把 s 标记为 upstream 的下游，只能在 s 被返回给调用方之前调用
+func (s Stream[T]) linked(upstream ...*control) Stream[T] {
+	if s.ctl == nil {
+		s.ctl = newControl()
+	}
+	s.ctl.upstream = append(s.ctl.upstream, upstream...)
+	return s
+}
+
+// errLinked 同 linked，但是 s 被取消时不会取消 upstream，只从 upstream 获取错误
+func (s Stream[T]) errLinked(upstream ...*control) Stream[T] {
+	if s.ctl == nil {
+		s.ctl = newControl()
+	}
+	s.ctl.errUpstream = append(s.ctl.errUpstream, upstream...)
+	return s
+}
+
+// controls 返回多个流的 control
+func controls[T any](streams []Stream[T]) []*control {
+	res := make([]*control, 0, len(streams))
+	for _, s := range streams {
+		res = append(res, s.ctl)
+	}
+	return res
+}
+
+// Err 返回流水线(当前流及其上游)中产生的第一个错误，比如 MapConcurrent 中 fn 返回的错误
+// 应在终止操作结束之后调用，没有错误时返回 nil
+func (s Stream[T]) Err() error {
+	return s.ctl.Err()
+}
+
+// ToSliceE 同 ToSlice，同时返回流水线中产生的第一个错误
+func (s Stream[T]) ToSliceE() ([]T, error) {
+	res := s.ToSlice()
+	return res, s.Err()
+}
+
+// ForEachE 同 ForEach，同时返回流水线中产生的第一个错误
+func (s Stream[T]) ForEachE(fn func(item T)) error {
+	s.ForEach(fn)
+	return s.Err()
+}
+
+/*
+ToChannel 把元素按顺序发送到 ch，ch 不会被关闭
+ctx 被取消时停止发送，上游不再继续生产，返回已经发送的元素个数和 ctx.Err()；否则返回流水线中产生的第一个错误
+
+eg:
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		n, err := s.ToChannel(ctx, out)
+		...
+	}()
+*/
+func (s Stream[T]) ToChannel(ctx context.Context, ch chan<- T) (int64, error) {
+	var n int64
+	var err error
+	s.ForEachWhile(func(item T) bool {
+		select {
+		case ch <- item:
+			n++
+			return true
+		case <-ctx.Done():
+			err = ctx.Err()
+			return false
+		}
+	})
+	if err == nil {
+		err = s.Err()
+	}
+	return n, err
+}
@@ -0,0 +1,33 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestToChannel(t *testing.T) {
+	ch := make(chan int, 3)
+	n, err := Of(1, 2, 3).ToChannel(context.Background(), ch)
+	if n != 3 || err != nil {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if a, b, c := <-ch, <-ch, <-ch; a != 1 || b != 2 || c != 3 {
+		t.Fatalf("got %d %d %d", a, b, c)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = make(chan int)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err = Iterate(0, func(i int) int { return i + 1 }).ToChannel(ctx, ch)
+	}()
+	<-ch
+	<-ch
+	cancel()
+	<-done
+	if n != 2 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+}
@@ -0,0 +1,91 @@
+package stream
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	reuseCheck   atomic.Bool
+	reuseHandler atomic.Value
+	// consumed 记录已经被终止操作消费过的流(以 source 通道为 key)及其调用位置
+	consumed sync.Map
+)
+
+// ReuseError 在一个已经被消费过的流上再次执行终止操作时产生的错误
+type ReuseError struct {
+	// FirstCallSite 第一次执行终止操作的位置
+	FirstCallSite string
+	// CallSite 再次执行终止操作的位置
+	CallSite string
+}
+
+func (e *ReuseError) Error() string {
+	return fmt.Sprintf("stream: terminal operation at %s on a stream already consumed at %s", e.CallSite, e.FirstCallSite)
+}
+
+/*
+EnableReuseCheck 开启调试模式，检测在已经被消费过的流上再次执行终止操作(ForEach、ToSlice、Count 等)
+Stream 只能被消费一次，重复消费会得到空的结果或者阻塞，开启后会把两次调用的位置通过 ReuseError 报告出来
+handler 为 nil 时直接 panic，否则调用 handler 处理错误后继续执行
+
+注意：开启后会记录每一个被消费过的流，只适合在测试和调试时使用
+
+eg:
+
+	stream.EnableReuseCheck(nil)
+	defer stream.DisableReuseCheck()
+*/
+func EnableReuseCheck(handler func(err *ReuseError)) {
+	reuseHandler.Store(handler)
+	reuseCheck.Store(true)
+}
+
+// DisableReuseCheck 关闭调试模式，并清空已记录的流
+func DisableReuseCheck() {
+	reuseCheck.Store(false)
+	consumed.Range(func(key, _ any) bool {
+		consumed.Delete(key)
+		return true
+	})
+}
+
+// checkConsumed 终止操作开始时调用，标记流已经被消费
+func (s Stream[T]) checkConsumed() {
+	if !reuseCheck.Load() || s.source == nil {
+		return
+	}
+	site := callSite()
+	first, loaded := consumed.LoadOrStore(s.source, site)
+	if !loaded {
+		return
+	}
+	err := &ReuseError{FirstCallSite: first.(string), CallSite: site}
+	if handler, _ := reuseHandler.Load().(func(err *ReuseError)); handler != nil {
+		handler(err)
+		return
+	}
+	panic(err)
+}
+
+// callSite 返回调用栈中第一个不在本包(非测试文件)中的位置
+func callSite() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	_, self, _, _ := runtime.Caller(0)
+	dir := filepath.Dir(self)
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != dir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
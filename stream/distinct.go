@@ -0,0 +1,162 @@
+package stream
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+)
+
+// DistinctBounded 有界去重，最多记住 maxKeys 个最近出现的 key，超出时按 LRU 淘汰最久未出现的 key
+// 注意：key 被淘汰后再次出现会被当作新元素再次输出，即只保证在最近 maxKeys 个不同 key 的范围内不重复
+func (s Stream[T]) DistinctBounded(fn func(item T) any, maxKeys int) Stream[T] {
+	if maxKeys <= 0 {
+		panic("maxKeys must be positive")
+	}
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		order := list.New()
+		keys := make(map[any]*list.Element, maxKeys)
+		for item := range s.source {
+			key := fn(item)
+			if e, ok := keys[key]; ok {
+				order.MoveToFront(e)
+				continue
+			}
+			if order.Len() >= maxKeys {
+				oldest := order.Back()
+				order.Remove(oldest)
+				delete(keys, oldest.Value)
+			}
+			keys[key] = order.PushFront(key)
+			source <- item
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// DistinctWithin 按时间窗口去重，同一个 key 在上一次输出后的 ttl 时间内再次出现会被丢弃，
+// 超过 ttl 后会再次输出。过期的 key 会被及时清理，内存只与 ttl 内出现的不同 key 的数量有关
+func (s Stream[T]) DistinctWithin(fn func(item T) any, ttl time.Duration) Stream[T] {
+	return s.distinctWithin(fn, ttl, time.Now)
+}
+
+func (s Stream[T]) distinctWithin(fn func(item T) any, ttl time.Duration, now func() time.Time) Stream[T] {
+	if ttl <= 0 {
+		panic("ttl must be positive")
+	}
+	type entry struct {
+		key  any
+		seen time.Time
+	}
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		// order 按输出时间从旧到新排列，便于从头部清理过期的 key
+		order := list.New()
+		keys := make(map[any]*list.Element)
+		for item := range s.source {
+			t := now()
+			for e := order.Front(); e != nil && t.Sub(e.Value.(entry).seen) >= ttl; e = order.Front() {
+				order.Remove(e)
+				delete(keys, e.Value.(entry).key)
+			}
+			key := fn(item)
+			if _, ok := keys[key]; ok {
+				continue
+			}
+			keys[key] = order.PushBack(entry{key: key, seen: t})
+			source <- item
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// DistinctApprox 基于布隆过滤器的近似去重，内存固定，适合 key 数量巨大的场景
+// expectedN 为预计的不同 key 的数量，fpRate 为期望的误判率(0,1)
+// 注意：布隆过滤器不会漏判，所以重复的元素一定会被丢弃；但存在误判，
+// 一个从未出现过的 key 可能以约 fpRate 的概率被认为已经出现而被丢弃，实际的 key 数量超过 expectedN 时误判率会升高。
+// key 通过 fmt.Sprint 的结果计算哈希，因此不同 key 的字符串形式需要不同
+func (s Stream[T]) DistinctApprox(fn func(item T) any, expectedN int, fpRate float64) Stream[T] {
+	filter := newBloomFilter(expectedN, fpRate)
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		for item := range s.source {
+			if filter.testAndAdd(fn(item)) {
+				continue
+			}
+			source <- item
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+func newBloomFilter(expectedN int, fpRate float64) *bloomFilter {
+	if expectedN <= 0 {
+		panic("expectedN must be positive")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("fpRate must be in (0, 1)")
+	}
+	// m = -n*ln(p)/(ln2)^2, k = m/n*ln2
+	m := uint64(math.Ceil(-float64(expectedN) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(expectedN) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// testAndAdd 返回 key 之前是否(可能)已经存在，并把 key 加入过滤器
+func (f *bloomFilter) testAndAdd(key any) bool {
+	h1, h2 := bloomHash(key)
+	exists := true
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if f.bits[word]&mask == 0 {
+			exists = false
+			f.bits[word] |= mask
+		}
+	}
+	return exists
+}
+
+// bloomHash 双重哈希，基于 hashKey 拆分出两个哈希值
+func bloomHash(key any) (uint64, uint64) {
+	sum := hashKey(key)
+	return sum, (sum>>32 | sum<<32) | 1
+}
+
+// hashKey 计算任意 key 的 fnv-1a 哈希值，非字符串的 key 使用 fmt.Sprint 的结果
+func hashKey(key any) uint64 {
+	h := fnv.New64a()
+	switch v := key.(type) {
+	case string:
+		_, _ = h.Write([]byte(v))
+	case []byte:
+		_, _ = h.Write(v)
+	default:
+		_, _ = fmt.Fprint(h, v)
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,55 @@
+package stream
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDistinctBounded(t *testing.T) {
+	res := Of(1, 2, 1, 3, 1, 4, 2, 5).DistinctBounded(func(item int) any {
+		return item
+	}, 2).ToSlice()
+	// 容量为2，2 在 3、1、4 出现后已被淘汰，会再次输出
+	if want := []int{1, 2, 3, 4, 2, 5}; !reflect.DeepEqual(res, want) {
+		t.Errorf("DistinctBounded() = %v, want %v", res, want)
+	}
+}
+
+func TestDistinctWithin(t *testing.T) {
+	base := time.Unix(0, 0)
+	clock := []time.Duration{0, 1, 2, 5, 6, 12}
+	i := 0
+	now := func() time.Time {
+		d := clock[i]
+		i++
+		return base.Add(d * time.Second)
+	}
+	res := Of("a", "b", "a", "a", "b", "a").distinctWithin(func(item string) any {
+		return item
+	}, 5*time.Second, now).ToSlice()
+	if want := []string{"a", "b", "a", "b", "a"}; !reflect.DeepEqual(res, want) {
+		t.Errorf("DistinctWithin() = %v, want %v", res, want)
+	}
+}
+
+func TestDistinctApprox(t *testing.T) {
+	items := make([]int, 0, 2000)
+	for i := 0; i < 1000; i++ {
+		items = append(items, i, i)
+	}
+	res := Of(items...).DistinctApprox(func(item int) any {
+		return item
+	}, 1000, 0.01).ToSlice()
+	seen := make(map[int]struct{})
+	for _, v := range res {
+		if _, ok := seen[v]; ok {
+			t.Fatalf("DistinctApprox() emitted duplicate %d", v)
+		}
+		seen[v] = struct{}{}
+	}
+	// 误判的元素数量不应明显超过 expectedN*fpRate
+	if lost := 1000 - len(res); lost > 20 {
+		t.Errorf("DistinctApprox() dropped %d of 1000 unique items, want at most 20", lost)
+	}
+}
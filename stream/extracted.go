@@ -1,9 +1,13 @@
 package stream
 
-import "github.com/todocoder/go-stream/collectors"
+import (
+	"github.com/todocoder/go-stream/collectors"
+	"runtime"
+	"sync"
+)
 
 /*
-Map stream 流 类型转换方法
+Map stream 流 类型转换方法，惰性执行并保持元素的顺序，可以用在 Iterate、Generate 等无限流之后
 
 eg:
 
@@ -21,12 +25,14 @@ res := Map(Of(
 	fmt.Println(res)
 */
 func Map[T any, R any](s Stream[T], mapper func(T) R) Stream[R] {
-	mapped := make([]R, 0)
-
-	for el := range s.source {
-		mapped = append(mapped, mapper(el))
-	}
-	return Of(mapped...)
+	source := make(chan R)
+	GoSafe(func() {
+		defer close(source)
+		for el := range s.source {
+			source <- mapper(el)
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
 }
 
 func FlatMap[T any, R any](s Stream[T], mapper func(T) Stream[R]) Stream[R] {
@@ -40,7 +46,7 @@ func FlatMap[T any, R any](s Stream[T], mapper func(T) Stream[R]) Stream[R] {
 		newEl = append(newEl, str.ToSlice()...)
 	}
 
-	return Of(newEl...)
+	return Of(newEl...).linked(append([]*control{s.ctl}, controls(streams)...)...)
 }
 
 func GroupingBy[T any, K string | int | int32 | int64, R any](s Stream[T], keyMapper func(T) K, valueMapper func(T) R, opts ...OptFunc[R]) map[K][]R {
@@ -58,9 +64,40 @@ func GroupingBy[T any, K string | int | int32 | int64, R any](s Stream[T], keyMa
 }
 
 func Collect[T any, A any, R any](s Stream[T], collector collectors.Collector[T, A, R]) R {
+	s.checkConsumed()
 	temp := collector.Supplier()()
 	for item := range s.source {
 		collector.Accumulator()(temp, item)
 	}
 	return collector.Finisher()(temp)
 }
+
+// walk 同 Walk，但是可以输出不同类型的元素，并行流使用多个协程处理
+func walk[T any, R any](s Stream[T], fn func(item T, pipe chan<- R)) Stream[R] {
+	var workers = 1
+	if s.isParallel {
+		workers = runtime.NumCPU() * 2
+	}
+	pipe := make(chan R, workers)
+	go func() {
+		defer close(pipe)
+		var wg sync.WaitGroup
+		// 这里是个占位类型
+		pool := make(chan struct{}, workers)
+		for item := range s.source {
+			val := item
+			// 这里是个占位类型值
+			pool <- struct{}{}
+			wg.Add(1)
+			GoSafe(func() {
+				defer func() {
+					wg.Done()
+					<-pool
+				}()
+				fn(val, pipe)
+			})
+		}
+		wg.Wait()
+	}()
+	return Range(pipe, s.isParallel).linked(s.ctl)
+}
@@ -0,0 +1,160 @@
+package stream
+
+import "github.com/todocoder/go-stream/collectors"
+
+/*
+OfFromE 通过 generate 惰性生产元素创建流，用于实现各种数据源(比如 stream/io 包)
+emit 在下游结束(比如 Limit 已经满足)时返回 false，此时 generate 应当立即返回并释放资源
+generate 返回的错误可以在终止操作之后通过 Err() 获取
+
+eg:
+
+	s := OfFromE(func(emit func(string) bool) error {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() && emit(scanner.Text()) {
+		}
+		return scanner.Err()
+	})
+	lines, err := s.ToSliceE()
+*/
+func OfFromE[T any](generate func(emit func(item T) bool) error) Stream[T] {
+	source := make(chan T)
+	res := Range(source, false)
+	GoSafe(func() {
+		defer close(source)
+		res.ctl.fail(generate(func(item T) bool {
+			select {
+			case source <- item:
+				return true
+			case <-res.ctl.Done():
+				return false
+			}
+		}))
+	})
+	return res
+}
+
+// generate 同 OfFromE，用于不会产生错误的生成器
+func generate[T any](gen func(emit func(item T) bool)) Stream[T] {
+	return OfFromE(func(emit func(T) bool) error {
+		gen(emit)
+		return nil
+	})
+}
+
+/*
+Iterate 生成 seed, next(seed), next(next(seed))... 的无限流，需要配合 Limit、TakeWhile 等短路操作使用
+
+eg:
+
+	// 1 2 4 8 16
+	res := Iterate(1, func(i int) int { return i * 2 }).Limit(5).ToSlice()
+*/
+func Iterate[T any](seed T, next func(T) T) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		for item := seed; emit(item); item = next(item) {
+		}
+	})
+}
+
+// IterateWhile 同 Iterate，hasNext 返回 false 时结束
+func IterateWhile[T any](seed T, hasNext func(T) bool, next func(T) T) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		for item := seed; hasNext(item) && emit(item); item = next(item) {
+		}
+	})
+}
+
+// Generate 不断调用 supplier 生成元素的无限流，需要配合 Limit、TakeWhile 等短路操作使用
+func Generate[T any](supplier func() T) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		for emit(supplier()) {
+		}
+	})
+}
+
+/*
+Unfold 从初始状态 state 开始，每次调用 fn 生成一个元素和下一个状态，fn 返回 false 时结束
+
+eg:
+
+	// 斐波那契数列 0 1 1 2 3 5 8
+	res := Unfold([2]int{0, 1}, func(s [2]int) (int, [2]int, bool) {
+		return s[0], [2]int{s[1], s[0] + s[1]}, true
+	}).Limit(7).ToSlice()
+*/
+func Unfold[S any, T any](state S, fn func(state S) (T, S, bool)) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		for {
+			item, next, ok := fn(state)
+			if !ok || !emit(item) {
+				return
+			}
+			state = next
+		}
+	})
+}
+
+// Repeat 重复输出 n 次 v，n 小于 0 时无限重复
+func Repeat[T any](v T, n int64) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		for i := int64(0); n < 0 || i < n; i++ {
+			if !emit(v) {
+				return
+			}
+		}
+	})
+}
+
+// Cycle 循环输出 s 中的元素，第一遍时缓存 s 的元素，之后从缓存中重复输出，s 为空时结束
+func Cycle[T any](s Stream[T]) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		var items []T
+		for item := range s.source {
+			items = append(items, item)
+			if !emit(item) {
+				s.stop()
+				return
+			}
+		}
+		if len(items) == 0 {
+			return
+		}
+		for {
+			for _, item := range items {
+				if !emit(item) {
+					return
+				}
+			}
+		}
+	}).linked(s.ctl)
+}
+
+/*
+IntRange 生成 [start, end) 之间间隔为 step 的数字，step 为负数时从大到小生成 (end, start]
+
+eg:
+
+	// 0 2 4 6 8
+	res := IntRange(0, 10, 2).ToSlice()
+	// 5 4 3 2 1
+	res := IntRange(5, 0, -1).ToSlice()
+*/
+func IntRange[N collectors.Number](start, end, step N) Stream[N] {
+	if step == 0 {
+		panic("step must not be zero")
+	}
+	return generate(func(emit func(N) bool) {
+		for i := start; (step > 0 && i < end) || (step < 0 && i > end); {
+			if !emit(i) {
+				return
+			}
+			next := i + step
+			// 整数溢出
+			if (step > 0 && next <= i) || (step < 0 && next >= i) {
+				return
+			}
+			i = next
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIterate(t *testing.T) {
+	res := Iterate(1, func(i int) int { return i * 2 }).Limit(5).ToSlice()
+	if want := []int{1, 2, 4, 8, 16}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	res = IterateWhile(1, func(i int) bool { return i < 20 }, func(i int) int { return i * 3 }).ToSlice()
+	if want := []int{1, 3, 9}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	n := 0
+	res := Generate(func() int {
+		n++
+		return n
+	}).TakeWhile(func(i int) bool { return i == 3 }).ToSlice()
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestUnfold(t *testing.T) {
+	res := Unfold([2]int{0, 1}, func(s [2]int) (int, [2]int, bool) {
+		return s[0], [2]int{s[1], s[0] + s[1]}, true
+	}).Limit(7).ToSlice()
+	if want := []int{0, 1, 1, 2, 3, 5, 8}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	res = Unfold(3, func(s int) (int, int, bool) {
+		return s, s - 1, s > 0
+	}).ToSlice()
+	if want := []int{3, 2, 1}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestRepeatAndCycle(t *testing.T) {
+	if res := Repeat("a", 3).ToSlice(); !reflect.DeepEqual(res, []string{"a", "a", "a"}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := Repeat("a", -1).Limit(2).ToSlice(); !reflect.DeepEqual(res, []string{"a", "a"}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := Cycle(Of(1, 2, 3)).Limit(7).ToSlice(); !reflect.DeepEqual(res, []int{1, 2, 3, 1, 2, 3, 1}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := Cycle(Of[int]()).ToSlice(); len(res) != 0 {
+		t.Fatalf("got %v, want empty", res)
+	}
+}
+
+func TestIntRange(t *testing.T) {
+	if res := IntRange(0, 10, 3).ToSlice(); !reflect.DeepEqual(res, []int{0, 3, 6, 9}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := IntRange(5, 0, -2).ToSlice(); !reflect.DeepEqual(res, []int{5, 3, 1}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := IntRange(0.0, 1.0, 0.25).ToSlice(); !reflect.DeepEqual(res, []float64{0, 0.25, 0.5, 0.75}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := IntRange[int8](100, 127, 20).ToSlice(); !reflect.DeepEqual(res, []int8{100, 120}) {
+		t.Fatalf("got %v", res)
+	}
+	if res := IntRange(0, 0, 1).ToSlice(); len(res) != 0 {
+		t.Fatalf("got %v, want empty", res)
+	}
+}
+
+func TestGeneratorNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		Map(Iterate(0, func(i int) int { return i + 1 }), func(i int) int { return i * 2 }).Limit(3).ToSlice()
+		Generate(func() int { return 1 }).Filter(func(int) bool { return true }).FindFirst()
+		Cycle(IntRange(0, 3, 1)).AnyMatch(func(i int) bool { return i == 2 })
+		Repeat(1, -1).TakeWhile(func(i int) bool { return true }).Count()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("goroutines leaked: before %d, after %d", before, n)
+	}
+}
+
+func TestForEachWhile(t *testing.T) {
+	var res []int
+	Iterate(1, func(i int) int { return i + 1 }).ForEachWhile(func(i int) bool {
+		res = append(res, i)
+		return i < 3
+	})
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
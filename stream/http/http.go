@@ -0,0 +1,203 @@
+/*
+Package http 提供在 HTTP 服务中增量输出流(NDJSON、Server-Sent Events)，以及在客户端把这样的响应读取为流的方法
+
+eg:
+
+	func listOrders(w nethttp.ResponseWriter, r *nethttp.Request) {
+		s := sql.FromRowsStruct[Order](rows)
+		if _, err := http.ServeNDJSON(w, r, s); err != nil {
+			log.Println(err)
+		}
+	}
+*/
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/todocoder/go-stream/stream"
+	sio "github.com/todocoder/go-stream/stream/io"
+)
+
+// Event 一个 Server-Sent Event，只有 Data 时可以只设置 Data
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry 客户端断开之后重连的等待时间(毫秒)，0 表示不设置
+	Retry int
+}
+
+// serve 按顺序把元素通过 write 写到 w 中，每个元素之后 flush
+// 客户端断开(r.Context() 结束)或者写入失败时停止流水线，返回写出的元素个数和第一个错误
+func serve[T any](w nethttp.ResponseWriter, r *nethttp.Request, s stream.Stream[T], write func(item T) error) (int64, error) {
+	rc := nethttp.NewResponseController(w)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	items := make(chan T)
+	sent := make(chan error, 1)
+	go func() {
+		defer close(items)
+		_, err := s.ToChannel(ctx, items)
+		sent <- err
+	}()
+
+	var n int64
+	var err error
+	for item := range items {
+		if err = write(item); err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			cancel()
+			go func() {
+				for range items {
+				}
+			}()
+			break
+		}
+		n++
+	}
+	if streamErr := <-sent; err == nil {
+		err = streamErr
+	}
+	return n, err
+}
+
+/*
+ServeNDJSON 把流中的元素编码为 NDJSON 增量的写入响应，每个元素之后 flush
+会设置 Content-Type: application/x-ndjson，客户端断开(r.Context() 结束)时停止流水线，返回写出的元素个数和第一个错误
+*/
+func ServeNDJSON[T any](w nethttp.ResponseWriter, r *nethttp.Request, s stream.Stream[T]) (int64, error) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	enc := json.NewEncoder(w)
+	return serve(w, r, s, func(item T) error {
+		return enc.Encode(item)
+	})
+}
+
+/*
+ServeSSE 把流中的元素通过 eventFn 转换为 Server-Sent Events 增量的写入响应，每个事件之后 flush
+会设置 Content-Type: text/event-stream 等响应头，客户端断开时停止流水线，返回写出的事件个数和第一个错误
+
+eg:
+
+	http.ServeSSE(w, r, prices, func(p Price) http.Event {
+		data, _ := json.Marshal(p)
+		return http.Event{Event: "price", Data: string(data)}
+	})
+*/
+func ServeSSE[T any](w nethttp.ResponseWriter, r *nethttp.Request, s stream.Stream[T], eventFn func(item T) Event) (int64, error) {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	return serve(w, r, s, func(item T) error {
+		_, err := w.Write(formatEvent(eventFn(item)))
+		return err
+	})
+}
+
+func formatEvent(e Event) []byte {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.Itoa(e.Retry) + "\n")
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return []byte(b.String())
+}
+
+func checkStatus(resp *nethttp.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("stream/http: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+/*
+FromNDJSONResponse 逐行解码 NDJSON 响应，选项和错误的处理同 io.DecodeJSONLines
+响应的状态码不是 2xx 时返回空流，Err() 返回该错误；resp.Body 在流结束或者下游结束时关闭
+*/
+func FromNDJSONResponse[T any](resp *nethttp.Response, opts ...sio.Option) stream.Stream[T] {
+	return stream.OfFromE(func(emit func(T) bool) error {
+		defer resp.Body.Close()
+		if err := checkStatus(resp); err != nil {
+			return err
+		}
+		items := sio.DecodeJSONLines[T](resp.Body, opts...)
+		items.ForEachWhile(emit)
+		return items.Err()
+	})
+}
+
+/*
+FromSSE 读取 Server-Sent Events 响应，每个事件输出一次，多行的 data 以 \n 连接，注释和没有 data 的事件被忽略
+响应的状态码不是 2xx 时返回空流，Err() 返回该错误；resp.Body 在流结束或者下游结束时关闭
+
+eg:
+
+	resp, err := nethttp.Get(url)
+	...
+	http.FromSSE(resp).Filter(func(e http.Event) bool {
+		return e.Event == "price"
+	}).ForEach(handle)
+*/
+func FromSSE(resp *nethttp.Response) stream.Stream[Event] {
+	return stream.OfFromE(func(emit func(Event) bool) error {
+		defer resp.Body.Close()
+		if err := checkStatus(resp); err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(resp.Body)
+		var e Event
+		var data []string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				if data != nil {
+					e.Data = strings.Join(data, "\n")
+					if !emit(e) {
+						return nil
+					}
+				}
+				e, data = Event{ID: e.ID}, nil
+				continue
+			}
+			if strings.HasPrefix(line, ":") {
+				continue
+			}
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				e.ID = value
+			case "event":
+				e.Event = value
+			case "data":
+				data = append(data, value)
+			case "retry":
+				if retry, err := strconv.Atoi(value); err == nil {
+					e.Retry = retry
+				}
+			}
+		}
+		return scanner.Err()
+	})
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestNDJSON(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if n, err := ServeNDJSON(w, r, stream.Of(item{1, "a"}, item{2, "b"})); n != 2 || err != nil {
+			t.Errorf("n = %d, err = %v", n, err)
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := nethttp.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	res, err := FromNDJSONResponse[item](resp).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []item{{1, "a"}, {2, "b"}}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		ServeSSE(w, r, stream.Of("a", "b\nc"), func(s string) Event {
+			return Event{ID: s[:1], Event: "msg", Data: s, Retry: 1000}
+		})
+	}))
+	defer srv.Close()
+
+	resp, err := nethttp.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	res, err := FromSSE(resp).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{{ID: "a", Event: "msg", Data: "a", Retry: 1000}, {ID: "b", Event: "msg", Data: "b\nc", Retry: 1000}}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %+v, want %+v", res, want)
+	}
+}
+
+func TestFromSSEParse(t *testing.T) {
+	body := ": comment\nevent: ping\n\ndata: 1\nid: 7\n\ndata:2\ndata: 3\n\n"
+	resp := &nethttp.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
+	res := FromSSE(resp).ToSlice()
+	want := []Event{{ID: "7", Data: "1"}, {ID: "7", Data: "2\n3"}}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %+v, want %+v", res, want)
+	}
+}
+
+func TestServeClientDisconnect(t *testing.T) {
+	done := make(chan error, 1)
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		s := stream.Map(stream.Iterate(0, func(i int) int { return i + 1 }), func(i int) string {
+			return strconv.Itoa(i)
+		})
+		_, err := ServeSSE(w, r, s, func(s string) Event {
+			return Event{Data: s}
+		})
+		done <- err
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := FromSSE(resp).Limit(3).ToSlice(); len(res) != 3 || res[2].Data != "2" {
+		t.Fatalf("got %+v", res)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("want error after client disconnect")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not stop after client disconnect")
+	}
+}
+
+func TestUnexpectedStatus(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		nethttp.Error(w, "nope", nethttp.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	resp, err := nethttp.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = FromNDJSONResponse[item](resp).ToSliceE()
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("err = %v, want status error", err)
+	}
+}
+
+func TestServeWriteError(t *testing.T) {
+	w := &failingResponse{ResponseRecorder: httptest.NewRecorder()}
+	r := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+	n, err := ServeNDJSON(w, r, stream.Iterate(0, func(i int) int { return i + 1 }))
+	if n != 2 || !errors.Is(err, errWrite) {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+}
+
+var errWrite = errors.New("connection reset")
+
+// failingResponse 写入两次之后失败
+type failingResponse struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *failingResponse) Write(p []byte) (int, error) {
+	if w.writes++; w.writes > 2 {
+		return 0, errWrite
+	}
+	return w.ResponseRecorder.Write(p)
+}
@@ -0,0 +1,213 @@
+package io
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	goio "io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+// ErrEntryClosed 再次打开已经读取并关闭的 tar 条目
+var ErrEntryClosed = errors.New("stream/io: archive entry has already been read")
+
+// ArchiveEntry 压缩包中的一个条目(文件或者目录)
+type ArchiveEntry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+
+	open func() (goio.ReadCloser, error)
+}
+
+// IsDir 是否为目录
+func (e ArchiveEntry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// Open 打开条目的内容，读取结束后需要调用 Close
+func (e ArchiveEntry) Open() (goio.ReadCloser, error) {
+	return e.open()
+}
+
+// Lines 按行读取条目的内容，规则同 Lines
+func (e ArchiveEntry) Lines(opts ...Option) stream.Stream[string] {
+	o := newOptions(opts)
+	return stream.Map(stream.OfFromE(func(emit func([]byte) bool) error {
+		rc, err := e.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return scan(rc, bufio.ScanLines, o, emit)
+	}), func(token []byte) string {
+		return string(token)
+	})
+}
+
+/*
+TarEntries 按顺序输出 tar 包中的条目，压缩包损坏时流结束，Err() 返回该错误
+
+tar 只能顺序读取，读取下一个条目之前：
+正在读取(已经 Open 还没有 Close)的条目会阻塞后面的条目，因此打开之后必须 Close；
+还没有被打开的条目的内容会先被读入内存，之后仍然可以通过 Open 读取，因此很大的条目会占用同样大小的内存
+
+eg:
+
+	err := io.GzipTarEntries(f).Filter(func(e io.ArchiveEntry) bool {
+		return strings.HasSuffix(e.Name, ".log")
+	}).ForEachE(func(e io.ArchiveEntry) {
+		errors := e.Lines().Filter(isError).Count()
+		...
+	})
+*/
+func TarEntries(r goio.Reader) stream.Stream[ArchiveEntry] {
+	return stream.OfFromE(func(emit func(ArchiveEntry) bool) error {
+		return tarEntries(r, emit)
+	})
+}
+
+// GzipTarEntries 同 TarEntries，用于 .tar.gz/.tgz
+func GzipTarEntries(r goio.Reader) stream.Stream[ArchiveEntry] {
+	return stream.OfFromE(func(emit func(ArchiveEntry) bool) error {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("stream/io: gzip: %w", err)
+		}
+		defer gz.Close()
+		return tarEntries(gz, emit)
+	})
+}
+
+func tarEntries(r goio.Reader, emit func(ArchiveEntry) bool) error {
+	tr := tar.NewReader(r)
+	var body *tarBody
+	for {
+		// 上一个条目的内容在 tr.Next 之后就无法再读取
+		if body != nil {
+			if err := body.release(); err != nil {
+				return fmt.Errorf("stream/io: tar: %w", err)
+			}
+		}
+		hdr, err := tr.Next()
+		if err == goio.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stream/io: tar: %w", err)
+		}
+		body = &tarBody{tr: tr}
+		body.cond = sync.NewCond(&body.mu)
+		info := hdr.FileInfo()
+		if !emit(ArchiveEntry{Name: hdr.Name, Size: hdr.Size, Mode: info.Mode(), ModTime: hdr.ModTime, open: body.open}) {
+			return nil
+		}
+	}
+}
+
+type tarBodyState int
+
+const (
+	tarBodyIdle tarBodyState = iota
+	tarBodyOpen
+	tarBodyClosed
+	tarBodyBuffered
+)
+
+// tarBody tar 条目的内容，当前条目直接从 tar.Reader 读取，读取下一个条目之前还没有打开时读入内存
+type tarBody struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	state tarBodyState
+	tr    *tar.Reader
+	buf   []byte
+}
+
+func (b *tarBody) open() (goio.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case tarBodyIdle:
+		b.state = tarBodyOpen
+		return &tarBodyReader{body: b}, nil
+	case tarBodyBuffered:
+		return goio.NopCloser(bytes.NewReader(b.buf)), nil
+	}
+	return nil, ErrEntryClosed
+}
+
+// release 等待正在读取的内容被关闭，还没有打开的内容读入内存
+func (b *tarBody) release() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.state == tarBodyOpen {
+		b.cond.Wait()
+	}
+	if b.state != tarBodyIdle {
+		return nil
+	}
+	buf, err := goio.ReadAll(b.tr)
+	if err != nil {
+		return err
+	}
+	b.buf = buf
+	b.state = tarBodyBuffered
+	return nil
+}
+
+type tarBodyReader struct {
+	body   *tarBody
+	closed bool
+}
+
+func (r *tarBodyReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, ErrEntryClosed
+	}
+	return r.body.tr.Read(p)
+}
+
+func (r *tarBodyReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.body.mu.Lock()
+	r.body.state = tarBodyClosed
+	r.body.cond.Broadcast()
+	r.body.mu.Unlock()
+	return nil
+}
+
+// ZipEntries 输出 zip 包中的条目，zip 可以随机读取，条目的内容可以在任意时间、多次打开
+// 压缩包损坏时流结束，Err() 返回该错误
+func ZipEntries(r goio.ReaderAt, size int64) stream.Stream[ArchiveEntry] {
+	return stream.OfFromE(func(emit func(ArchiveEntry) bool) error {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return fmt.Errorf("stream/io: zip: %w", err)
+		}
+		for _, f := range zr.File {
+			entry := ArchiveEntry{
+				Name:    f.Name,
+				Size:    int64(f.UncompressedSize64),
+				Mode:    f.Mode(),
+				ModTime: f.Modified,
+				open:    f.Open,
+			}
+			if !emit(entry) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,184 @@
+package io
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	goio "io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var archiveFiles = []struct {
+	name, body string
+}{
+	{"logs/", ""},
+	{"logs/a.log", "a1\na2\n"},
+	{"logs/b.log", "b1\nERROR b2\nb3\n"},
+	{"README", "readme"},
+}
+
+func tarBytes(t *testing.T) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range archiveFiles {
+		hdr := &tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.body)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(f.name, "/") {
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTarEntries(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string][]string{}
+	// ForEach 在其他协程中处理条目，条目的内容仍然可以读取
+	err := TarEntries(bytes.NewReader(tarBytes(t))).Filter(func(e ArchiveEntry) bool {
+		return !e.IsDir()
+	}).ForEachE(func(e ArchiveEntry) {
+		lines := e.Lines().ToSlice()
+		mu.Lock()
+		got[e.Name] = lines
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"logs/a.log": {"a1", "a2"}, "logs/b.log": {"b1", "ERROR b2", "b3"}, "README": {"readme"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTarEntriesSequential(t *testing.T) {
+	var bodies []string
+	TarEntries(bytes.NewReader(tarBytes(t))).ForEachWhile(func(e ArchiveEntry) bool {
+		rc, err := e.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := goio.ReadAll(rc)
+		rc.Close()
+		bodies = append(bodies, string(data))
+		return true
+	})
+	if want := []string{"", "a1\na2\n", "b1\nERROR b2\nb3\n", "readme"}; !reflect.DeepEqual(bodies, want) {
+		t.Fatalf("got %q, want %q", bodies, want)
+	}
+}
+
+func TestGzipTarEntries(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(tarBytes(t))
+	gz.Close()
+	names := entryNames(GzipTarEntries(&buf).ToSlice())
+	if want := []string{"logs/", "logs/a.log", "logs/b.log", "README"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+}
+
+func entryNames(entries []ArchiveEntry) []string {
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func TestZipEntries(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range archiveFiles {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f.body))
+	}
+	zw.Close()
+	data := buf.Bytes()
+	entries, err := ZipEntries(bytes.NewReader(data), int64(len(data))).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || !entries[0].IsDir() || entries[2].Size != 15 {
+		t.Fatalf("got %+v", entries)
+	}
+	// zip 的条目可以在流结束之后读取
+	if n := entries[2].Lines().Filter(func(line string) bool {
+		return strings.HasPrefix(line, "ERROR")
+	}).Count(); n != 1 {
+		t.Fatalf("got %d error lines, want 1", n)
+	}
+}
+
+func TestCorruptArchives(t *testing.T) {
+	garbage := []byte("this is not an archive")
+	if _, err := GzipTarEntries(bytes.NewReader(garbage)).ToSliceE(); err == nil || !strings.Contains(err.Error(), "gzip") {
+		t.Fatalf("gzip err = %v", err)
+	}
+	if _, err := ZipEntries(bytes.NewReader(garbage), int64(len(garbage))).ToSliceE(); err == nil || !strings.Contains(err.Error(), "zip") {
+		t.Fatalf("zip err = %v", err)
+	}
+	data := tarBytes(t)
+	entries, err := TarEntries(bytes.NewReader(data[:700])).ToSliceE()
+	if err == nil || !strings.Contains(err.Error(), "tar") {
+		t.Fatalf("tar err = %v, entries %d", err, len(entries))
+	}
+}
+
+func TestTarBody(t *testing.T) {
+	tr := tar.NewReader(bytes.NewReader(tarBytes(t)))
+	tr.Next()
+	tr.Next()
+
+	// 直接从 tar.Reader 读取，关闭之后不能再次打开
+	b := &tarBody{tr: tr}
+	b.cond = sync.NewCond(&b.mu)
+	rc, err := b.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	released := make(chan struct{})
+	go func() {
+		b.release()
+		close(released)
+	}()
+	data, _ := goio.ReadAll(rc)
+	rc.Close()
+	<-released
+	if string(data) != "a1\na2\n" {
+		t.Fatalf("got %q", data)
+	}
+	if _, err := b.open(); err != ErrEntryClosed {
+		t.Fatalf("err = %v, want ErrEntryClosed", err)
+	}
+
+	// 没有打开时读入内存，可以多次打开
+	tr.Next()
+	b = &tarBody{tr: tr}
+	b.cond = sync.NewCond(&b.mu)
+	if err := b.release(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		rc, _ := b.open()
+		if data, _ := goio.ReadAll(rc); string(data) != "b1\nERROR b2\nb3\n" {
+			t.Fatalf("got %q", data)
+		}
+	}
+}
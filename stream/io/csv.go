@@ -0,0 +1,348 @@
+package io
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	goio "io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/todocoder/go-stream/stream"
+	"github.com/todocoder/go-stream/utils"
+)
+
+// WithComma 设置 CSV 的分隔符，默认为 ','
+func WithComma(comma rune) Option {
+	return func(o *options) {
+		o.comma = comma
+	}
+}
+
+// WithoutHeader CSV 没有表头，ReadCSV 按字段的声明顺序对应每一列，WriteCSV 不输出表头
+func WithoutHeader() Option {
+	return func(o *options) {
+		o.noHeader = true
+	}
+}
+
+// StrictColumns ReadCSV 严格匹配列：表头中的每一列都必须对应一个字段，每个字段也都必须出现在表头中，
+// 每一行的列数都必须和表头(或者第一行)相同
+// 默认忽略没有对应字段的列，缺少的字段为零值
+func StrictColumns() Option {
+	return func(o *options) {
+		o.strictColumns = true
+	}
+}
+
+// csvField 结构体中对应 CSV 一列的字段
+type csvField struct {
+	name  string
+	index []int
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// csvFields 返回结构体 t 中对应 CSV 列的字段，列名为 csv tag，没有 tag 时为字段名，tag 为 "-" 时忽略该字段
+func csvFields(t reflect.Type) ([]csvField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("stream/io: csv type %s is not a struct", t)
+	}
+	var fields []csvField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("csv")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if !csvSupported(f.Type) {
+			return nil, fmt.Errorf("stream/io: csv field %s has unsupported type %s", f.Name, f.Type)
+		}
+		fields = append(fields, csvField{name: name, index: f.Index})
+	}
+	return fields, nil
+}
+
+func csvSupported(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setCSVField 把单元格的值 s 转换后设置到字段 v 中，空的单元格为零值
+func setCSVField(v reflect.Value, s string) error {
+	if s == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setCSVField(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := utils.ToAnyE[time.Duration](s)
+		v.SetInt(int64(d))
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := utils.ToAnyE[bool](s)
+		v.SetBool(b)
+		return err
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := utils.ToAnyE[int64](s)
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("value %s overflows %s", s, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := utils.ToAnyE[uint64](s)
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(i) {
+			return fmt.Errorf("value %s overflows %s", s, v.Type())
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := utils.ToAnyE[float64](s)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+// formatCSVField 把字段 v 转换为单元格的值，nil 指针为空
+func formatCSVField(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+/*
+ReadCSV 读取 CSV，按表头把每一行转换为结构体 T，列名通过字段的 csv tag 指定，没有 tag 时为字段名
+单元格的值通过 utils.ToAnyE 转换为字段的类型，也支持实现了 encoding.TextUnmarshaler 的类型(比如 time.Time)
+默认遇到转换失败的行时结束流，Err() 返回带行号的 *DecodeError，设置 SkipInvalid 时跳过该行
+
+eg:
+
+	type User struct {
+		ID    int64  `csv:"id"`
+		Name  string `csv:"name"`
+		Email string `csv:"-"`
+	}
+	users, err := io.ReadCSV[User](f, io.WithComma(';'), io.SkipInvalid(func(err *io.DecodeError) {
+		log.Println(err) // stream/io: line 3: column "id": ...
+	})).ToSliceE()
+*/
+func ReadCSV[T any](r goio.Reader, opts ...Option) stream.Stream[T] {
+	o := newOptions(opts)
+	return stream.OfFromE(func(emit func(T) bool) error {
+		fields, err := csvFields(reflect.TypeOf((*T)(nil)).Elem())
+		if err != nil {
+			return err
+		}
+		reader := csv.NewReader(r)
+		reader.Comma = o.comma
+		reader.ReuseRecord = true
+		if !o.strictColumns {
+			reader.FieldsPerRecord = -1
+		}
+
+		// columns[i] 为第 i 列对应的字段，nil 表示忽略该列
+		columns := make([]*csvField, len(fields))
+		for i := range fields {
+			columns[i] = &fields[i]
+		}
+		if !o.noHeader {
+			header, err := reader.Read()
+			if err == goio.EOF {
+				return nil
+			}
+			if err != nil {
+				return &DecodeError{Line: 1, Err: err}
+			}
+			if columns, err = csvColumns(header, fields, o.strictColumns); err != nil {
+				return &DecodeError{Line: 1, Err: err}
+			}
+		}
+
+		for {
+			record, err := reader.Read()
+			if err == goio.EOF {
+				return nil
+			}
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				if err := o.invalid(&DecodeError{Line: pe.StartLine, Err: pe.Err}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			line, _ := reader.FieldPos(0)
+			var item T
+			if err := decodeCSVRecord(reflect.ValueOf(&item).Elem(), record, columns); err != nil {
+				if err := o.invalid(&DecodeError{Line: line, Err: err}); err != nil {
+					return err
+				}
+				continue
+			}
+			if !emit(item) {
+				return nil
+			}
+		}
+	})
+}
+
+// csvColumns 按表头找到每一列对应的字段
+func csvColumns(header []string, fields []csvField, strict bool) ([]*csvField, error) {
+	byName := make(map[string]*csvField, len(fields))
+	for i := range fields {
+		byName[fields[i].name] = &fields[i]
+	}
+	columns := make([]*csvField, len(header))
+	for i, name := range header {
+		f, ok := byName[name]
+		if !ok && strict {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[i] = f
+		delete(byName, name)
+	}
+	if strict {
+		for _, f := range fields {
+			if _, ok := byName[f.name]; ok {
+				return nil, fmt.Errorf("missing column %q", f.name)
+			}
+		}
+	}
+	return columns, nil
+}
+
+func decodeCSVRecord(v reflect.Value, record []string, columns []*csvField) error {
+	for i, cell := range record {
+		if i >= len(columns) || columns[i] == nil {
+			continue
+		}
+		if err := setCSVField(v.FieldByIndex(columns[i].index), cell); err != nil {
+			return fmt.Errorf("column %q: %w", columns[i].name, err)
+		}
+	}
+	return nil
+}
+
+/*
+WriteCSV 把流中的结构体写成 CSV，默认先写出表头，列名的规则同 ReadCSV
+写入失败时停止消费流，返回已经写出的行数(不包括表头)、字节数和错误
+
+eg:
+
+	stats, err := io.WriteCSV(stream.Of(users...), w)
+*/
+func WriteCSV[T any](s stream.Stream[T], w goio.Writer, opts ...Option) (WriteStats, error) {
+	o := newOptions(opts)
+	cw := &countingWriter{w: w}
+	var stats WriteStats
+	fields, err := csvFields(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		// 不再消费，通知上游停止
+		s.ForEachWhile(func(T) bool { return false })
+		return stats, err
+	}
+	writer := csv.NewWriter(cw)
+	writer.Comma = o.comma
+	record := make([]string, len(fields))
+	if !o.noHeader {
+		for i, f := range fields {
+			record[i] = f.name
+		}
+		err = writer.Write(record)
+	}
+	s.ForEachWhile(func(item T) bool {
+		if err != nil {
+			return false
+		}
+		v := reflect.ValueOf(item)
+		for i, f := range fields {
+			if record[i], err = formatCSVField(v.FieldByIndex(f.index)); err != nil {
+				err = fmt.Errorf("stream/io: column %q: %w", f.name, err)
+				return false
+			}
+		}
+		if err = writer.Write(record); err != nil {
+			return false
+		}
+		stats.Items++
+		return true
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err == nil {
+		err = s.Err()
+	}
+	stats.Bytes = cw.n
+	return stats, err
+}
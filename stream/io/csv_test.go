@@ -0,0 +1,114 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+type csvUser struct {
+	ID      int64         `csv:"id"`
+	Name    string        `csv:"name"`
+	Score   *float64      `csv:"score"`
+	Timeout time.Duration `csv:"timeout"`
+	Active  bool
+	Secret  string `csv:"-"`
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "name,id,extra,score,Active,timeout\n" +
+		"alice,1,x,9.5,true,1s\n" +
+		"bob,2,y,,false,\n"
+	res, err := ReadCSV[csvUser](strings.NewReader(input)).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	score := 9.5
+	want := []csvUser{
+		{ID: 1, Name: "alice", Score: &score, Timeout: time.Second, Active: true},
+		{ID: 2, Name: "bob"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %+v, want %+v", res, want)
+	}
+}
+
+func TestReadCSVWithoutHeader(t *testing.T) {
+	res, err := ReadCSV[csvUser](strings.NewReader("1;alice\n2;bob;3.5\n"), WithoutHeader(), WithComma(';')).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Name != "alice" || res[1].ID != 2 || *res[1].Score != 3.5 {
+		t.Fatalf("got %+v", res)
+	}
+}
+
+func TestReadCSVInvalidRow(t *testing.T) {
+	input := "id,name\n1,a\nx,b\n3,c\n"
+	res, err := ReadCSV[csvUser](strings.NewReader(input)).ToSliceE()
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 3 || !strings.Contains(err.Error(), `column "id"`) {
+		t.Fatalf("err = %v, want line 3 column id", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("got %+v, want 1 row", res)
+	}
+
+	var lines []int
+	res, err = ReadCSV[csvUser](strings.NewReader(input), SkipInvalid(func(err *DecodeError) {
+		lines = append(lines, err.Line)
+	})).ToSliceE()
+	if err != nil || len(res) != 2 || res[1].ID != 3 || !reflect.DeepEqual(lines, []int{3}) {
+		t.Fatalf("got %+v, err %v, invalid lines %v", res, err, lines)
+	}
+}
+
+func TestReadCSVStrict(t *testing.T) {
+	_, err := ReadCSV[csvUser](strings.NewReader("id,name,extra\n1,a,x\n"), StrictColumns()).ToSliceE()
+	if err == nil || !strings.Contains(err.Error(), `unknown column "extra"`) {
+		t.Fatalf("err = %v, want unknown column", err)
+	}
+	_, err = ReadCSV[csvUser](strings.NewReader("id,name\n1,a\n"), StrictColumns()).ToSliceE()
+	if err == nil || !strings.Contains(err.Error(), `missing column "score"`) {
+		t.Fatalf("err = %v, want missing column", err)
+	}
+
+	input := "id,name,score,timeout,Active\n1,a,1,1s,true\n2,b\n"
+	var de *DecodeError
+	res, err := ReadCSV[csvUser](strings.NewReader(input), StrictColumns(), SkipInvalid(func(err *DecodeError) {
+		de = err
+	})).ToSliceE()
+	if err != nil || len(res) != 1 || de == nil || de.Line != 3 {
+		t.Fatalf("got %+v, err %v, invalid %v", res, err, de)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	score := 1.5
+	var buf bytes.Buffer
+	stats, err := WriteCSV(stream.Of(
+		csvUser{ID: 1, Name: "a,b", Score: &score, Timeout: time.Minute, Active: true, Secret: "x"},
+		csvUser{ID: 2, Name: "c"},
+	), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,score,timeout,Active\n1,\"a,b\",1.5,1m0s,true\n2,c,,0s,false\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+	if stats.Items != 2 || stats.Bytes != int64(len(want)) {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// 写出的内容可以再读回来
+	res, err := ReadCSV[csvUser](&buf).ToSliceE()
+	if err != nil || len(res) != 2 || res[0].Name != "a,b" || *res[0].Score != 1.5 || res[0].Timeout != time.Minute {
+		t.Fatalf("got %+v, err %v", res, err)
+	}
+}
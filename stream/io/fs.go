@@ -0,0 +1,192 @@
+package io
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+// SymlinkPolicy WalkFS 遇到符号链接时的处理方式
+type SymlinkPolicy int
+
+const (
+	// SymlinkInclude 输出符号链接本身，不进入它指向的目录(默认的行为，同 fs.WalkDir)
+	SymlinkInclude SymlinkPolicy = iota
+	// SymlinkSkip 忽略符号链接
+	SymlinkSkip
+	// SymlinkFollow 跟随符号链接，指向目录时进入该目录，指向祖先目录(循环)时不再进入
+	SymlinkFollow
+)
+
+type walkOptions struct {
+	include  []string
+	exclude  []string
+	maxDepth int
+	symlinks SymlinkPolicy
+	files    bool
+}
+
+// Include WalkFS 只输出匹配任意一个 glob 的文件(不影响目录的遍历)
+// 包含 / 的 glob 匹配完整路径，否则匹配文件名，语法同 path.Match
+func Include(patterns ...string) Option {
+	return func(o *options) {
+		o.walk.include = append(o.walk.include, patterns...)
+	}
+}
+
+// Exclude WalkFS 忽略匹配任意一个 glob 的文件和目录，被忽略的目录不会被遍历，glob 的规则同 Include
+func Exclude(patterns ...string) Option {
+	return func(o *options) {
+		o.walk.exclude = append(o.walk.exclude, patterns...)
+	}
+}
+
+// MaxDepth WalkFS 最多遍历到 root 下第 n 层，root 的深度为 0，默认不限制
+func MaxDepth(n int) Option {
+	return func(o *options) {
+		o.walk.maxDepth = n
+	}
+}
+
+// WithSymlinks 设置 WalkFS 遇到符号链接时的处理方式
+func WithSymlinks(policy SymlinkPolicy) Option {
+	return func(o *options) {
+		o.walk.symlinks = policy
+	}
+}
+
+// FilesOnly WalkFS 只输出文件，不输出目录
+func FilesOnly() Option {
+	return func(o *options) {
+		o.walk.files = true
+	}
+}
+
+// FileEntry WalkFS 遍历到的文件或者目录
+type FileEntry struct {
+	fs.DirEntry
+	// Path fsys 中的路径
+	Path string
+	// Depth 相对 root 的深度，root 为 0
+	Depth int
+
+	fsys fs.FS
+}
+
+// FileContent ReadFiles 读取的文件内容
+type FileContent struct {
+	FileEntry
+	Data []byte
+}
+
+func matchAny(patterns []string, p string) bool {
+	name := path.Base(p)
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = p
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+WalkFS 按字典序深度优先遍历 fsys 中 root 下的文件和目录(包括 root 本身)，可以使用 testing/fstest.MapFS 测试
+读取目录失败时流结束，Err() 返回该错误
+
+eg:
+
+	files := io.WalkFS(os.DirFS("."), "src", io.Include("*.go"), io.Exclude("vendor", "*_test.go"), io.FilesOnly())
+	contents, err := io.ReadFiles(files, 16).Parallel().Filter(hasTODO).ToSliceE()
+*/
+func WalkFS(fsys fs.FS, root string, opts ...Option) stream.Stream[FileEntry] {
+	o := newOptions(opts)
+	return stream.OfFromE(func(emit func(FileEntry) bool) error {
+		info, err := fs.Stat(fsys, root)
+		if err != nil {
+			return err
+		}
+		w := &walker{fsys: fsys, o: o.walk, emit: emit}
+		_, err = w.walk(root, fs.FileInfoToDirEntry(info), 0, nil)
+		return err
+	})
+}
+
+type walker struct {
+	fsys fs.FS
+	o    walkOptions
+	emit func(FileEntry) bool
+}
+
+// walk 遍历 p，ancestors 为 p 的所有祖先目录，用于跟随符号链接时检测循环，返回 false 表示下游已经结束
+func (w *walker) walk(p string, d fs.DirEntry, depth int, ancestors []fs.FileInfo) (bool, error) {
+	if depth > 0 && matchAny(w.o.exclude, p) {
+		return true, nil
+	}
+	isDir := d.IsDir()
+	if d.Type()&fs.ModeSymlink != 0 {
+		switch w.o.symlinks {
+		case SymlinkSkip:
+			return true, nil
+		case SymlinkFollow:
+			info, err := fs.Stat(w.fsys, p)
+			if err != nil {
+				return false, err
+			}
+			d = fs.FileInfoToDirEntry(info)
+			isDir = d.IsDir()
+		}
+	}
+	emit := !isDir || !w.o.files
+	if !isDir && len(w.o.include) > 0 && !matchAny(w.o.include, p) {
+		emit = false
+	}
+	if emit && !w.emit(FileEntry{DirEntry: d, Path: p, Depth: depth, fsys: w.fsys}) {
+		return false, nil
+	}
+	if !isDir || (w.o.maxDepth >= 0 && depth >= w.o.maxDepth) {
+		return true, nil
+	}
+
+	info, err := fs.Stat(w.fsys, p)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range ancestors {
+		if os.SameFile(a, info) {
+			return true, nil
+		}
+	}
+	entries, err := fs.ReadDir(w.fsys, p)
+	if err != nil {
+		return false, err
+	}
+	ancestors = append(ancestors, info)
+	for _, e := range entries {
+		if ok, err := w.walk(path.Join(p, e.Name()), e, depth+1, ancestors); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+/*
+ReadFiles 读取 s 中每个文件的内容(目录会被忽略)，最多同时打开 maxOpen 个文件，输出的顺序和 s 相同
+读取失败时流结束，Err() 返回该错误
+*/
+func ReadFiles(s stream.Stream[FileEntry], maxOpen int) stream.Stream[FileContent] {
+	files := s.Filter(func(e FileEntry) bool {
+		return !e.IsDir()
+	})
+	return stream.MapConcurrent(files, maxOpen, func(_ context.Context, e FileEntry) (FileContent, error) {
+		data, err := fs.ReadFile(e.fsys, e.Path)
+		return FileContent{FileEntry: e, Data: data}, err
+	})
+}
@@ -0,0 +1,93 @@
+package io
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+var testFS = fstest.MapFS{
+	"src/main.go":           {Data: []byte("package main")},
+	"src/main_test.go":      {Data: []byte("package main_test")},
+	"src/util/str.go":       {Data: []byte("package util")},
+	"src/util/deep/x.go":    {Data: []byte("package deep")},
+	"src/vendor/lib/lib.go": {Data: []byte("package lib")},
+	"src/README.md":         {Data: []byte("# readme")},
+	"docs/index.md":         {Data: []byte("# docs")},
+}
+
+func paths(s stream.Stream[FileEntry]) []string {
+	return stream.Map(s, func(e FileEntry) string {
+		return e.Path
+	}).ToSlice()
+}
+
+func TestWalkFS(t *testing.T) {
+	got := paths(WalkFS(testFS, "src"))
+	want := []string{"src", "src/README.md", "src/main.go", "src/main_test.go", "src/util", "src/util/deep",
+		"src/util/deep/x.go", "src/util/str.go", "src/vendor", "src/vendor/lib", "src/vendor/lib/lib.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	got = paths(WalkFS(testFS, ".", Include("*.go"), Exclude("vendor", "*_test.go"), FilesOnly()))
+	want = []string{"src/main.go", "src/util/deep/x.go", "src/util/str.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	got = paths(WalkFS(testFS, "src", MaxDepth(1), Include("src/util*"), Exclude("src/vendor")))
+	want = []string{"src", "src/util"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if _, err := WalkFS(testFS, "missing").ToSliceE(); !os.IsNotExist(err) {
+		t.Fatalf("err = %v, want not exist", err)
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	res, err := ReadFiles(WalkFS(testFS, "src/util").Parallel(), 2).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, c := range res {
+		got[c.Path] = string(c.Data)
+	}
+	want := map[string]string{"src/util/str.go": "package util", "src/util/deep/x.go": "package deep"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestWalkFSSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "f.txt"), []byte("f"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// a/loop 指向 a 自己
+	if err := os.Symlink(".", filepath.Join(dir, "a", "loop")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	fsys := os.DirFS(dir)
+
+	if got, want := paths(WalkFS(fsys, ".")), []string{".", "a", "a/f.txt", "a/loop"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("include: got %v, want %v", got, want)
+	}
+	if got, want := paths(WalkFS(fsys, ".", WithSymlinks(SymlinkSkip))), []string{".", "a", "a/f.txt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("skip: got %v, want %v", got, want)
+	}
+	res := WalkFS(fsys, ".", WithSymlinks(SymlinkFollow)).ToSlice()
+	if len(res) != 4 || res[3].Path != "a/loop" || !res[3].IsDir() {
+		t.Fatalf("follow: got %v", res)
+	}
+}
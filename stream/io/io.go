@@ -0,0 +1,129 @@
+/*
+Package io 提供从 io.Reader、文件等创建流的数据源
+
+读取过程中产生的错误(比如 bufio.ErrTooLong)不会丢失，可以在终止操作之后通过 Err() 获取，
+或者直接使用 ToSliceE、ForEachE 等返回错误的终止操作
+
+eg:
+
+	lines, err := io.FileLines("app.log").Filter(func(line string) bool {
+		return strings.Contains(line, "ERROR")
+	}).ToSliceE()
+*/
+package io
+
+import (
+	"bufio"
+	goio "io"
+	"os"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+// Option 设置数据源的选项
+type Option func(*options)
+
+type options struct {
+	maxTokenSize  int
+	skipInvalid   bool
+	onInvalid     func(err *DecodeError)
+	comma         rune
+	noHeader      bool
+	strictColumns bool
+	walk          walkOptions
+}
+
+// WithMaxTokenSize 设置 Scan、Lines 单个元素(比如一行)的最大长度，默认为 bufio.MaxScanTokenSize(64KB)
+// 超过时流结束，Err() 返回 bufio.ErrTooLong
+func WithMaxTokenSize(n int) Option {
+	return func(o *options) {
+		o.maxTokenSize = n
+	}
+}
+
+// SkipInvalid 解码失败的记录被跳过并交给 report(可以为 nil)，而不是结束流
+// 输入本身损坏(比如 JSON 数组的语法错误)导致无法继续读取时仍然会结束流
+func SkipInvalid(report func(err *DecodeError)) Option {
+	return func(o *options) {
+		o.skipInvalid = true
+		o.onInvalid = report
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{maxTokenSize: bufio.MaxScanTokenSize, comma: ',', walk: walkOptions{maxDepth: -1}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Lines 按行读取 r，行尾的 \n 或 \r\n 会被去掉
+func Lines(r goio.Reader, opts ...Option) stream.Stream[string] {
+	return stream.Map(Scan(r, bufio.ScanLines, opts...), func(token []byte) string {
+		return string(token)
+	})
+}
+
+// Scan 使用 split 把 r 切分成一个个元素，比如 bufio.ScanWords、bufio.ScanRunes
+// 每个元素都是新分配的切片，可以安全的保存
+func Scan(r goio.Reader, split bufio.SplitFunc, opts ...Option) stream.Stream[[]byte] {
+	o := newOptions(opts)
+	return stream.OfFromE(func(emit func([]byte) bool) error {
+		return scan(r, split, o, emit)
+	})
+}
+
+func scan(r goio.Reader, split bufio.SplitFunc, o options, emit func([]byte) bool) error {
+	scanner := bufio.NewScanner(r)
+	initial := 4096
+	if o.maxTokenSize < initial {
+		initial = o.maxTokenSize
+	}
+	scanner.Buffer(make([]byte, 0, initial), o.maxTokenSize)
+	scanner.Split(split)
+	for scanner.Scan() {
+		if !emit(append([]byte(nil), scanner.Bytes()...)) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Chunks 把 r 按 size 个字节切分，最后一块可能小于 size
+func Chunks(r goio.Reader, size int) stream.Stream[[]byte] {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	return stream.OfFromE(func(emit func([]byte) bool) error {
+		for {
+			buf := make([]byte, size)
+			n, err := goio.ReadFull(r, buf)
+			if n > 0 && !emit(buf[:n]) {
+				return nil
+			}
+			if err == goio.EOF || err == goio.ErrUnexpectedEOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// FileLines 按行读取文件 path，文件在流结束或者下游结束(比如 Limit 已经满足)时关闭
+// 打开文件失败时返回空流，Err() 返回打开文件的错误
+func FileLines(path string, opts ...Option) stream.Stream[string] {
+	o := newOptions(opts)
+	return stream.Map(stream.OfFromE(func(emit func([]byte) bool) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return scan(f, bufio.ScanLines, o, emit)
+	}), func(token []byte) string {
+		return string(token)
+	})
+}
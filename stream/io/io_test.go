@@ -0,0 +1,65 @@
+package io
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	res, err := Lines(strings.NewReader("a\r\nb\n\nc")).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "", "c"}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %q, want %q", res, want)
+	}
+}
+
+func TestScan(t *testing.T) {
+	res := Scan(strings.NewReader("go  stream\tio\n"), bufio.ScanWords).ToSlice()
+	if want := [][]byte{[]byte("go"), []byte("stream"), []byte("io")}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %q, want %q", res, want)
+	}
+}
+
+func TestLinesTooLong(t *testing.T) {
+	input := "short\n" + strings.Repeat("x", 100) + "\nnext\n"
+	res, err := Lines(strings.NewReader(input), WithMaxTokenSize(16)).ToSliceE()
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("err = %v, want bufio.ErrTooLong", err)
+	}
+	if want := []string{"short"}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %q, want %q", res, want)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	res := Chunks(strings.NewReader("abcdefg"), 3).ToSlice()
+	if want := [][]byte{[]byte("abc"), []byte("def"), []byte("g")}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %q, want %q", res, want)
+	}
+}
+
+func TestFileLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.log")
+	if err := os.WriteFile(path, []byte("1\n2\n3\n4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := FileLines(path).Limit(2).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1", "2"}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %q, want %q", res, want)
+	}
+
+	_, err = FileLines(filepath.Join(t.TempDir(), "missing.log")).ToSliceE()
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("err = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,140 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	goio "io"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+// DecodeError 解码一条记录失败的错误，记录了它在输入中的位置
+type DecodeError struct {
+	// Line 记录所在的行号，从 1 开始，没有行号时(比如 DecodeJSONArray)为 0
+	Line int
+	// Offset 记录在输入中的字节偏移
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("stream/io: line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("stream/io: offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// invalid 处理一条解码失败的记录，返回 nil 时跳过该记录继续读取
+func (o options) invalid(err *DecodeError) error {
+	if !o.skipInvalid {
+		return err
+	}
+	if o.onInvalid != nil {
+		o.onInvalid(err)
+	}
+	return nil
+}
+
+/*
+DecodeJSONLines 逐行解码 NDJSON(每行一个 JSON)，空行会被忽略
+默认遇到解码失败的行时结束流，Err() 返回带行号的 *DecodeError，设置 SkipInvalid 时跳过该行
+
+eg:
+
+	events, err := io.DecodeJSONLines[Event](f, io.SkipInvalid(func(err *io.DecodeError) {
+		log.Println(err)
+	})).ToSliceE()
+*/
+func DecodeJSONLines[T any](r goio.Reader, opts ...Option) stream.Stream[T] {
+	o := newOptions(opts)
+	return stream.OfFromE(func(emit func(T) bool) error {
+		line := 0
+		var offset int64
+		var err error
+		scanErr := scan(r, scanLinesWithEOL, o, func(token []byte) bool {
+			line++
+			start := offset
+			offset += int64(len(token))
+			token = bytes.TrimSpace(token)
+			if len(token) == 0 {
+				return true
+			}
+			var v T
+			if e := json.Unmarshal(token, &v); e != nil {
+				err = o.invalid(&DecodeError{Line: line, Offset: start, Err: e})
+				return err == nil
+			}
+			return emit(v)
+		})
+		if err != nil {
+			return err
+		}
+		return scanErr
+	})
+}
+
+// scanLinesWithEOL 同 bufio.ScanLines，但是保留行尾，用来计算每行的偏移
+func scanLinesWithEOL(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+/*
+DecodeJSONArray 逐个解码顶层 JSON 数组中的元素，不需要把整个数组读入内存
+元素不能转换为 T 时的处理同 DecodeJSONLines，*DecodeError 中记录元素的字节偏移；
+输入不是数组或者有语法错误时结束流
+
+eg:
+
+	// [{"id":1},{"id":2},...]
+	n := io.DecodeJSONArray[Order](f).Filter(isPaid).Count()
+*/
+func DecodeJSONArray[T any](r goio.Reader, opts ...Option) stream.Stream[T] {
+	o := newOptions(opts)
+	return stream.OfFromE(func(emit func(T) bool) error {
+		dec := json.NewDecoder(bufio.NewReader(r))
+		tok, err := dec.Token()
+		if err != nil {
+			return &DecodeError{Offset: dec.InputOffset(), Err: err}
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return &DecodeError{Err: fmt.Errorf("expected JSON array, got %v", tok)}
+		}
+		for dec.More() {
+			offset := dec.InputOffset()
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return &DecodeError{Offset: offset, Err: err}
+			}
+			var v T
+			if err := json.Unmarshal(raw, &v); err != nil {
+				start := dec.InputOffset() - int64(len(raw))
+				if err := o.invalid(&DecodeError{Offset: start, Err: err}); err != nil {
+					return err
+				}
+				continue
+			}
+			if !emit(v) {
+				return nil
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return &DecodeError{Offset: dec.InputOffset(), Err: err}
+		}
+		return nil
+	})
+}
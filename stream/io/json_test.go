@@ -0,0 +1,87 @@
+package io
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDecodeJSONLines(t *testing.T) {
+	input := "{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"b\"}\r\n"
+	res, err := DecodeJSONLines[record](strings.NewReader(input)).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []record{{1, "a"}, {2, "b"}}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestDecodeJSONLinesInvalid(t *testing.T) {
+	input := "{\"id\":1}\n{\"id\":\"x\"}\n{oops\n{\"id\":4}\n"
+	res, err := DecodeJSONLines[record](strings.NewReader(input)).ToSliceE()
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 2 || de.Offset != 9 {
+		t.Fatalf("err = %v, want line 2 at offset 9", err)
+	}
+	if want := []record{{ID: 1}}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+
+	var lines []int
+	res, err = DecodeJSONLines[record](strings.NewReader(input), SkipInvalid(func(err *DecodeError) {
+		lines = append(lines, err.Line)
+	})).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []record{{ID: 1}, {ID: 4}}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 3}) {
+		t.Fatalf("invalid lines = %v, want [2 3]", lines)
+	}
+}
+
+func TestDecodeJSONArray(t *testing.T) {
+	input := ` [{"id":1,"name":"a"}, {"id":"x"}, {"id":3}] `
+	res, err := DecodeJSONArray[record](strings.NewReader(input)).ToSliceE()
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Offset != 23 {
+		t.Fatalf("err = %v, want error at offset 23", err)
+	}
+	if want := []record{{1, "a"}}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+
+	n := 0
+	res, err = DecodeJSONArray[record](strings.NewReader(input), SkipInvalid(func(*DecodeError) {
+		n++
+	})).ToSliceE()
+	if err != nil || n != 1 {
+		t.Fatalf("err = %v, skipped %d", err, n)
+	}
+	if want := []record{{1, "a"}, {ID: 3}}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+
+	if res := DecodeJSONArray[int](strings.NewReader("[1,2,3,4]")).Limit(2).ToSlice(); !reflect.DeepEqual(res, []int{1, 2}) {
+		t.Fatalf("got %v", res)
+	}
+}
+
+func TestDecodeJSONArrayCorrupt(t *testing.T) {
+	for _, input := range []string{`{"id":1}`, `[{"id":1}, {"id":`, `[1 2]`} {
+		_, err := DecodeJSONArray[any](strings.NewReader(input), SkipInvalid(nil)).ToSliceE()
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Fatalf("%s: err = %v, want *DecodeError", input, err)
+		}
+	}
+}
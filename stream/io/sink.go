@@ -0,0 +1,125 @@
+package io
+
+import (
+	"bufio"
+	"encoding/json"
+	goio "io"
+	"os"
+	"path/filepath"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+// WriteStats 写出的元素个数和字节数
+type WriteStats struct {
+	Items int64
+	Bytes int64
+}
+
+/*
+WriteLines 把每个元素通过 format 转换为一行写入 w
+写入失败时停止消费流，返回已经写出的元素个数、字节数和第一个错误(没有写入错误时为流水线中的错误)
+
+eg:
+
+	stats, err := io.WriteLines(s, os.Stdout, func(u User) string {
+		return u.Name
+	})
+*/
+func WriteLines[T any](s stream.Stream[T], w goio.Writer, format func(item T) string) (WriteStats, error) {
+	return writeEach(s, w, func(bw *bufio.Writer, item T) error {
+		if _, err := bw.WriteString(format(item)); err != nil {
+			return err
+		}
+		return bw.WriteByte('\n')
+	})
+}
+
+// EncodeJSONLines 把每个元素编码为一行 JSON(NDJSON)写入 w，返回值同 WriteLines
+func EncodeJSONLines[T any](s stream.Stream[T], w goio.Writer) (WriteStats, error) {
+	var enc *json.Encoder
+	return writeEach(s, w, func(bw *bufio.Writer, item T) error {
+		if enc == nil {
+			enc = json.NewEncoder(bw)
+			enc.SetEscapeHTML(false)
+		}
+		return enc.Encode(item)
+	})
+}
+
+// writeEach 按顺序把每个元素通过 write 写入 w 的缓冲中，第一次失败时停止
+func writeEach[T any](s stream.Stream[T], w goio.Writer, write func(bw *bufio.Writer, item T) error) (WriteStats, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	var stats WriteStats
+	var err error
+	s.ForEachWhile(func(item T) bool {
+		if err = write(bw, item); err != nil {
+			return false
+		}
+		stats.Items++
+		return true
+	})
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = s.Err()
+	}
+	stats.Bytes = cw.n
+	return stats, err
+}
+
+/*
+WriteFile 通过 encode(比如 EncodeJSONLines)把流写入文件 path
+先写入同一目录下的临时文件，全部成功之后再原子的重命名为 path，失败时删除临时文件，不会留下写了一半的文件
+新建的文件权限为 0644，path 已经存在时保留原来的权限
+
+eg:
+
+	stats, err := io.WriteFile(s, "events.jsonl", io.EncodeJSONLines[Event])
+*/
+func WriteFile[T any](s stream.Stream[T], path string,
+	encode func(s stream.Stream[T], w goio.Writer) (WriteStats, error)) (stats WriteStats, err error) {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		// 不再消费，通知上游停止
+		s.ForEachWhile(func(T) bool { return false })
+		return stats, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if stats, err = encode(s, f); err != nil {
+		return stats, err
+	}
+	if err = f.Chmod(mode); err != nil {
+		return stats, err
+	}
+	if err = f.Sync(); err != nil {
+		return stats, err
+	}
+	if err = f.Close(); err != nil {
+		return stats, err
+	}
+	return stats, os.Rename(f.Name(), path)
+}
+
+// countingWriter 记录写出的字节数
+type countingWriter struct {
+	w goio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,86 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	goio "io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+// failingWriter 写入 n 个字节之后失败
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteLines(t *testing.T) {
+	var buf bytes.Buffer
+	stats, err := WriteLines(stream.Of(1, 2, 3), &buf, strconv.Itoa)
+	if err != nil || buf.String() != "1\n2\n3\n" || stats != (WriteStats{Items: 3, Bytes: 6}) {
+		t.Fatalf("got %q, stats %+v, err %v", buf.String(), stats, err)
+	}
+}
+
+func TestWriteLinesError(t *testing.T) {
+	s := stream.Iterate(0, func(i int) int { return i + 1 })
+	stats, err := WriteLines(s, &failingWriter{n: 10000}, func(i int) string {
+		return "0123456789"
+	})
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("err = %v, want disk full", err)
+	}
+	if stats.Bytes != 10000 {
+		t.Fatalf("stats = %+v, want 10000 bytes", stats)
+	}
+}
+
+func TestEncodeJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	stats, err := EncodeJSONLines(stream.Of(record{1, "a<b"}, record{2, "c"}), &buf)
+	want := "{\"id\":1,\"name\":\"a<b\"}\n{\"id\":2,\"name\":\"c\"}\n"
+	if err != nil || buf.String() != want || stats.Items != 2 || stats.Bytes != int64(len(want)) {
+		t.Fatalf("got %q, stats %+v, err %v", buf.String(), stats, err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.jsonl")
+	stats, err := WriteFile(stream.Of(record{ID: 1}), path, EncodeJSONLines[record])
+	if err != nil || stats.Items != 1 {
+		t.Fatalf("stats %+v, err %v", stats, err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\"id\":1,\"name\":\"\"}\n" {
+		t.Fatalf("got %q", data)
+	}
+
+	// 失败时保留原来的文件，不留下临时文件
+	_, err = WriteFile(stream.Of(1, 2), path, func(s stream.Stream[int], w goio.Writer) (WriteStats, error) {
+		WriteLines(s, w, strconv.Itoa)
+		return WriteStats{}, errors.New("encode failed")
+	})
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, data) {
+		t.Fatalf("file changed to %q", after)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("got %d files, want 1", len(entries))
+	}
+}
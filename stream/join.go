@@ -0,0 +1,258 @@
+package stream
+
+// Pair 二元组，Join 等操作的结果类型
+type Pair[L any, R any] struct {
+	Left  L
+	Right R
+}
+
+// OfPair 创建一个二元组
+func OfPair[L any, R any](left L, right R) Pair[L, R] {
+	return Pair[L, R]{Left: left, Right: right}
+}
+
+/*
+Join 内连接，输出 lKey 与 rKey 相等的所有左右元素组合
+
+基于哈希实现：两边交替读取，先读完的一边(数据量较小的一边)用来建哈希表，另一边流式探测，
+所以输出顺序跟随探测的一边。如果两边都已经按 key 排好序，使用 JoinSorted 只需要很少的内存
+
+eg:
+
+	res := Join(Of(orders...), Of(customers...), func(o Order) int {
+		return o.customerId
+	}, func(c Customer) int {
+		return c.id
+	}).ToSlice()
+*/
+func Join[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K) Stream[Pair[L, R]] {
+	source := make(chan Pair[L, R])
+	GoSafe(func() {
+		defer close(source)
+		hashJoin(left, right, lKey, rKey, false, false, func(l Optional[L], r Optional[R]) {
+			source <- Pair[L, R]{Left: *l.v, Right: *r.v}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// LeftJoin 左外连接，左边没有匹配的元素也会输出，此时 Right 为空的 Optional
+func LeftJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K) Stream[Pair[L, Optional[R]]] {
+	source := make(chan Pair[L, Optional[R]])
+	GoSafe(func() {
+		defer close(source)
+		hashJoin(left, right, lKey, rKey, true, false, func(l Optional[L], r Optional[R]) {
+			source <- Pair[L, Optional[R]]{Left: *l.v, Right: r}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// RightJoin 右外连接，右边没有匹配的元素也会输出，此时 Left 为空的 Optional
+func RightJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K) Stream[Pair[Optional[L], R]] {
+	source := make(chan Pair[Optional[L], R])
+	GoSafe(func() {
+		defer close(source)
+		hashJoin(left, right, lKey, rKey, false, true, func(l Optional[L], r Optional[R]) {
+			source <- Pair[Optional[L], R]{Left: l, Right: *r.v}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// FullOuterJoin 全外连接，两边没有匹配的元素都会输出，缺失的一边为空的 Optional
+func FullOuterJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K) Stream[Pair[Optional[L], Optional[R]]] {
+	source := make(chan Pair[Optional[L], Optional[R]])
+	GoSafe(func() {
+		defer close(source)
+		hashJoin(left, right, lKey, rKey, true, true, func(l Optional[L], r Optional[R]) {
+			source <- Pair[Optional[L], Optional[R]]{Left: l, Right: r}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// SemiJoin 半连接，输出左边在右边存在匹配 key 的元素，每个左边元素最多输出一次
+func SemiJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K) Stream[L] {
+	return keyJoin(left, right, lKey, rKey, true)
+}
+
+// AntiJoin 反连接，输出左边在右边没有匹配 key 的元素
+func AntiJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K) Stream[L] {
+	return keyJoin(left, right, lKey, rKey, false)
+}
+
+func keyJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K, keep bool) Stream[L] {
+	source := make(chan L)
+	GoSafe(func() {
+		defer close(source)
+
+		keys := make(map[K]struct{})
+		for item := range right.source {
+			keys[rKey(item)] = struct{}{}
+		}
+		for item := range left.source {
+			if _, ok := keys[lKey(item)]; ok == keep {
+				source <- item
+			}
+		}
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// hashJoin 交替读取两边，用先读完的一边建哈希表，keepL/keepR 表示是否输出左/右边未匹配的元素
+func hashJoin[L any, R any, K comparable](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K,
+	keepL, keepR bool, emit func(l Optional[L], r Optional[R])) {
+	var lItems []L
+	var rItems []R
+	lOpen, rOpen := true, true
+	for lOpen && rOpen {
+		var l L
+		if l, lOpen = <-left.source; lOpen {
+			lItems = append(lItems, l)
+		}
+		var r R
+		if r, rOpen = <-right.source; rOpen {
+			rItems = append(rItems, r)
+		}
+	}
+	if !lOpen {
+		probeJoin(lItems, rItems, right.source, lKey, rKey, keepL, keepR, emit)
+		return
+	}
+	probeJoin(rItems, lItems, left.source, rKey, lKey, keepR, keepL, func(r Optional[R], l Optional[L]) {
+		emit(l, r)
+	})
+}
+
+// probeJoin 用 built 建哈希表，依次用 buffered 和 rest 中的元素探测
+func probeJoin[B any, P any, K comparable](built []B, buffered []P, rest <-chan P, bKey func(B) K, pKey func(P) K,
+	keepB, keepP bool, emit func(b Optional[B], p Optional[P])) {
+	table := make(map[K][]int, len(built))
+	for i, item := range built {
+		key := bKey(item)
+		table[key] = append(table[key], i)
+	}
+	matched := make([]bool, len(built))
+	probe := func(p P) {
+		indexes, ok := table[pKey(p)]
+		if !ok {
+			if keepP {
+				emit(Optional[B]{v: nil}, Optional[P]{v: &p})
+			}
+			return
+		}
+		for _, i := range indexes {
+			matched[i] = true
+			emit(Optional[B]{v: &built[i]}, Optional[P]{v: &p})
+		}
+	}
+	for _, p := range buffered {
+		probe(p)
+	}
+	for p := range rest {
+		probe(p)
+	}
+	if keepB {
+		for i := range built {
+			if !matched[i] {
+				emit(Optional[B]{v: &built[i]}, Optional[P]{v: nil})
+			}
+		}
+	}
+}
+
+// JoinSorted 基于归并的内连接，要求两边都已经按 key 升序排列(cmp 比较 key)，只缓存右边 key 相同的一组元素
+func JoinSorted[L any, R any, K any](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K, cmp func(K, K) int) Stream[Pair[L, R]] {
+	source := make(chan Pair[L, R])
+	GoSafe(func() {
+		defer close(source)
+		mergeJoin(left, right, lKey, rKey, cmp, false, false, func(l Optional[L], r Optional[R]) {
+			source <- Pair[L, R]{Left: *l.v, Right: *r.v}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// LeftJoinSorted 基于归并的左外连接，要求两边都已经按 key 升序排列
+func LeftJoinSorted[L any, R any, K any](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K, cmp func(K, K) int) Stream[Pair[L, Optional[R]]] {
+	source := make(chan Pair[L, Optional[R]])
+	GoSafe(func() {
+		defer close(source)
+		mergeJoin(left, right, lKey, rKey, cmp, true, false, func(l Optional[L], r Optional[R]) {
+			source <- Pair[L, Optional[R]]{Left: *l.v, Right: r}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// RightJoinSorted 基于归并的右外连接，要求两边都已经按 key 升序排列
+func RightJoinSorted[L any, R any, K any](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K, cmp func(K, K) int) Stream[Pair[Optional[L], R]] {
+	source := make(chan Pair[Optional[L], R])
+	GoSafe(func() {
+		defer close(source)
+		mergeJoin(left, right, lKey, rKey, cmp, false, true, func(l Optional[L], r Optional[R]) {
+			source <- Pair[Optional[L], R]{Left: l, Right: *r.v}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+// FullOuterJoinSorted 基于归并的全外连接，要求两边都已经按 key 升序排列
+func FullOuterJoinSorted[L any, R any, K any](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K, cmp func(K, K) int) Stream[Pair[Optional[L], Optional[R]]] {
+	source := make(chan Pair[Optional[L], Optional[R]])
+	GoSafe(func() {
+		defer close(source)
+		mergeJoin(left, right, lKey, rKey, cmp, true, true, func(l Optional[L], r Optional[R]) {
+			source <- Pair[Optional[L], Optional[R]]{Left: l, Right: r}
+		})
+	})
+	return Range(source, left.isParallel).linked(left.ctl, right.ctl)
+}
+
+func mergeJoin[L any, R any, K any](left Stream[L], right Stream[R], lKey func(L) K, rKey func(R) K, cmp func(K, K) int,
+	keepL, keepR bool, emit func(l Optional[L], r Optional[R])) {
+	l, lOk := <-left.source
+	r, rOk := <-right.source
+	for lOk && rOk {
+		lk, rk := lKey(l), rKey(r)
+		c := cmp(lk, rk)
+		if c < 0 {
+			if keepL {
+				item := l
+				emit(Optional[L]{v: &item}, Optional[R]{v: nil})
+			}
+			l, lOk = <-left.source
+			continue
+		}
+		if c > 0 {
+			if keepR {
+				item := r
+				emit(Optional[L]{v: nil}, Optional[R]{v: &item})
+			}
+			r, rOk = <-right.source
+			continue
+		}
+		// 收集右边 key 相同的一组元素，与左边 key 相同的元素两两组合
+		group := []R{r}
+		for r, rOk = <-right.source; rOk && cmp(rKey(r), rk) == 0; r, rOk = <-right.source {
+			group = append(group, r)
+		}
+		for ; lOk && cmp(lKey(l), rk) == 0; l, lOk = <-left.source {
+			item := l
+			for i := range group {
+				emit(Optional[L]{v: &item}, Optional[R]{v: &group[i]})
+			}
+		}
+	}
+	for ; lOk && keepL; l, lOk = <-left.source {
+		item := l
+		emit(Optional[L]{v: &item}, Optional[R]{v: nil})
+	}
+	for ; rOk && keepR; r, rOk = <-right.source {
+		item := r
+		emit(Optional[L]{v: nil}, Optional[R]{v: &item})
+	}
+	go drain(left.source)
+	go drain(right.source)
+}
@@ -0,0 +1,121 @@
+package stream
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type joinOrder struct {
+	id         int
+	customerId int
+}
+
+type joinCustomer struct {
+	id   int
+	name string
+}
+
+func joinFixture() (Stream[joinOrder], Stream[joinCustomer]) {
+	return Of(
+			joinOrder{id: 1, customerId: 1},
+			joinOrder{id: 2, customerId: 2},
+			joinOrder{id: 3, customerId: 1},
+			joinOrder{id: 4, customerId: 9},
+		), Of(
+			joinCustomer{id: 1, name: "tom"},
+			joinCustomer{id: 2, name: "jerry"},
+			joinCustomer{id: 3, name: "spike"},
+		)
+}
+
+func orderCustomerKey(o joinOrder) int {
+	return o.customerId
+}
+
+func customerKey(c joinCustomer) int {
+	return c.id
+}
+
+func formatJoin[L any, R any](pairs []Pair[L, R]) []string {
+	res := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		res = append(res, fmt.Sprintf("%v-%v", formatOptional(p.Left), formatOptional(p.Right)))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func formatOptional(v any) any {
+	switch o := v.(type) {
+	case Optional[joinOrder]:
+		return o.OrElse(joinOrder{})
+	case Optional[joinCustomer]:
+		return o.OrElse(joinCustomer{})
+	}
+	return v
+}
+
+func TestJoin(t *testing.T) {
+	orders, customers := joinFixture()
+	res := formatJoin(Join(orders, customers, orderCustomerKey, customerKey).ToSlice())
+	want := []string{"{1 1}-{1 tom}", "{2 2}-{2 jerry}", "{3 1}-{1 tom}"}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("Join() = %v, want %v", res, want)
+	}
+
+	orders, customers = joinFixture()
+	res = formatJoin(JoinSorted(orders.Sorted(func(a, b joinOrder) bool {
+		return a.customerId < b.customerId
+	}), customers, orderCustomerKey, customerKey, intCmp).ToSlice())
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("JoinSorted() = %v, want %v", res, want)
+	}
+}
+
+func TestOuterJoin(t *testing.T) {
+	orders, customers := joinFixture()
+	res := formatJoin(LeftJoin(orders, customers, orderCustomerKey, customerKey).ToSlice())
+	want := []string{"{1 1}-{1 tom}", "{2 2}-{2 jerry}", "{3 1}-{1 tom}", "{4 9}-{0 }"}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("LeftJoin() = %v, want %v", res, want)
+	}
+
+	orders, customers = joinFixture()
+	res = formatJoin(RightJoin(orders, customers, orderCustomerKey, customerKey).ToSlice())
+	want = []string{"{0 0}-{3 spike}", "{1 1}-{1 tom}", "{2 2}-{2 jerry}", "{3 1}-{1 tom}"}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("RightJoin() = %v, want %v", res, want)
+	}
+
+	orders, customers = joinFixture()
+	res = formatJoin(FullOuterJoin(orders, customers, orderCustomerKey, customerKey).ToSlice())
+	want = []string{"{0 0}-{3 spike}", "{1 1}-{1 tom}", "{2 2}-{2 jerry}", "{3 1}-{1 tom}", "{4 9}-{0 }"}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("FullOuterJoin() = %v, want %v", res, want)
+	}
+
+	orders, customers = joinFixture()
+	sortedOrders := orders.Sorted(func(a, b joinOrder) bool {
+		return a.customerId < b.customerId
+	})
+	res = formatJoin(FullOuterJoinSorted(sortedOrders, customers, orderCustomerKey, customerKey, intCmp).ToSlice())
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("FullOuterJoinSorted() = %v, want %v", res, want)
+	}
+}
+
+func TestSemiAntiJoin(t *testing.T) {
+	orders, customers := joinFixture()
+	semi := SemiJoin(customers, orders, customerKey, orderCustomerKey).ToSlice()
+	if want := []joinCustomer{{id: 1, name: "tom"}, {id: 2, name: "jerry"}}; !reflect.DeepEqual(semi, want) {
+		t.Errorf("SemiJoin() = %v, want %v", semi, want)
+	}
+
+	orders, customers = joinFixture()
+	anti := AntiJoin(orders, customers, orderCustomerKey, customerKey).ToSlice()
+	if want := []joinOrder{{id: 4, customerId: 9}}; !reflect.DeepEqual(anti, want) {
+		t.Errorf("AntiJoin() = %v, want %v", anti, want)
+	}
+}
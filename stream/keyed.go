@@ -0,0 +1,93 @@
+package stream
+
+import "sync"
+
+/*
+KeyedStream 按 key 分片的并行流，通过 ParallelByKey 创建
+每个元素按 key 的哈希值固定分配给一个工作协程，相同 key 的元素在同一个协程中按原来的顺序依次处理，
+不同 key 的元素在多个协程中并行处理
+*/
+type KeyedStream[T any] struct {
+	source  <-chan T
+	keyFn   func(item T) any
+	workers int
+	ctl     *control
+}
+
+// ParallelByKey 按 keyFn 计算的 key 把流分片到 workers 个工作协程上
+func (s Stream[T]) ParallelByKey(keyFn func(item T) any, workers int) KeyedStream[T] {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	s.checkConsumed()
+	return KeyedStream[T]{
+		source:  s.source,
+		keyFn:   keyFn,
+		workers: workers,
+		ctl:     s.ctl,
+	}
+}
+
+// ForEach 并行处理每个元素，相同 key 的元素按顺序处理，所有元素处理完后返回
+func (k KeyedStream[T]) ForEach(fn func(item T)) {
+	k.run(func(item T) {
+		fn(item)
+	})
+}
+
+// ForEachByKey 等同于 ParallelByKey(keyFn, workers).ForEach(fn)
+func (s Stream[T]) ForEachByKey(keyFn func(item T) any, workers int, fn func(item T)) {
+	s.ParallelByKey(keyFn, workers).ForEach(fn)
+}
+
+// MapByKey 按 key 分片并行转换，相同 key 的元素的结果保持原来的相对顺序，不同 key 之间的顺序不确定
+func MapByKey[T any, R any](k KeyedStream[T], mapper func(T) R) Stream[R] {
+	source := make(chan R, k.workers)
+	go func() {
+		defer close(source)
+		k.run(func(item T) {
+			source <- mapper(item)
+		})
+	}()
+	return Range(source, true).linked(k.ctl)
+}
+
+// run 把元素分发给各个工作协程，fn 中的 panic 会被恢复，不影响同一个协程中后续元素的处理
+func (k KeyedStream[T]) run(fn func(item T)) {
+	shard(k.source, func(item T) uint64 {
+		return hashKey(k.keyFn(item))
+	}, k.workers, func(in <-chan T) {
+		for item := range in {
+			val := item
+			RunSafe(func() {
+				fn(val)
+			})
+		}
+	})
+}
+
+// shard 按 hash 把 source 中的元素分发给 workers 个工作协程，每个协程通过 worker 顺序消费自己的分片，全部结束后返回
+func shard[T any](source <-chan T, hash func(T) uint64, workers int, worker func(in <-chan T)) {
+	shards := make([]chan T, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := range shards {
+		in := make(chan T, 1)
+		shards[i] = in
+		GoSafe(func() {
+			defer func() {
+				// worker 异常退出时继续消费，避免阻塞分发
+				drain(in)
+				wg.Done()
+			}()
+			worker(in)
+		})
+	}
+	for item := range source {
+		shards[hash(item)%uint64(workers)] <- item
+	}
+	for _, in := range shards {
+		close(in)
+	}
+	wg.Wait()
+}
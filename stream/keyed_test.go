@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type keyedEvent struct {
+	account string
+	seq     int
+}
+
+func keyedEvents() []keyedEvent {
+	var events []keyedEvent
+	for seq := 0; seq < 50; seq++ {
+		for _, account := range []string{"a", "b", "c", "d"} {
+			events = append(events, keyedEvent{account: account, seq: seq})
+		}
+	}
+	return events
+}
+
+func keyedAccount(e keyedEvent) any {
+	return e.account
+}
+
+func TestForEachByKey(t *testing.T) {
+	var mu sync.Mutex
+	applied := make(map[string][]int)
+	Of(keyedEvents()...).ForEachByKey(keyedAccount, 3, func(e keyedEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		applied[e.account] = append(applied[e.account], e.seq)
+	})
+	for account, seqs := range applied {
+		for i, seq := range seqs {
+			if seq != i {
+				t.Fatalf("account %s applied out of order: %v", account, seqs)
+			}
+		}
+	}
+	if len(applied) != 4 {
+		t.Errorf("ForEachByKey() applied %d accounts, want 4", len(applied))
+	}
+}
+
+func TestMapByKey(t *testing.T) {
+	res := MapByKey(Of(keyedEvents()...).ParallelByKey(keyedAccount, 4), func(e keyedEvent) string {
+		return fmt.Sprintf("%s:%d", e.account, e.seq)
+	}).ToSlice()
+	got := make(map[string][]string)
+	for _, r := range res {
+		got[r[:1]] = append(got[r[:1]], r)
+	}
+	for _, account := range []string{"a", "b", "c", "d"} {
+		want := make([]string, 0, 50)
+		for seq := 0; seq < 50; seq++ {
+			want = append(want, fmt.Sprintf("%s:%d", account, seq))
+		}
+		if !reflect.DeepEqual(got[account], want) {
+			t.Errorf("MapByKey() account %s = %v", account, got[account])
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package stream
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// MergeSorted k 路归并，把多个已经按 cmp 升序排列的流(比如按时间排好序的多个日志文件)合并成一个有序的流
+// 基于小顶堆实现，每个输入流同时只缓存一个元素，按需读取
+func MergeSorted[T any](cmp func(T, T) int, streams ...Stream[T]) Stream[T] {
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		h := &mergeHeap[T]{cmp: cmp}
+		for i, s := range streams {
+			if item, ok := <-s.source; ok {
+				h.items = append(h.items, mergeItem[T]{item: item, index: i})
+			}
+		}
+		heap.Init(h)
+		for h.Len() > 0 {
+			top := h.items[0]
+			source <- top.item
+			if item, ok := <-streams[top.index].source; ok {
+				h.items[0].item = item
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	})
+	return Range(source, isParallel(streams)).linked(controls(streams)...)
+}
+
+// Merge 合并多个流，任意一个输入流产生元素都会立即输出(fan-in)，输出顺序不确定
+func Merge[T any](streams ...Stream[T]) Stream[T] {
+	source := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(streams))
+	for _, s := range streams {
+		s := s
+		GoSafe(func() {
+			defer wg.Done()
+			for item := range s.source {
+				source <- item
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(source)
+	}()
+	return Range(source, isParallel(streams)).linked(controls(streams)...)
+}
+
+// Interleave 轮流从每个输入流中取一个元素输出，已经结束的流会被跳过
+func Interleave[T any](streams ...Stream[T]) Stream[T] {
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		active := make([]<-chan T, 0, len(streams))
+		for _, s := range streams {
+			active = append(active, s.source)
+		}
+		for len(active) > 0 {
+			next := active[:0]
+			for _, ch := range active {
+				if item, ok := <-ch; ok {
+					source <- item
+					next = append(next, ch)
+				}
+			}
+			active = next
+		}
+	})
+	return Range(source, isParallel(streams)).linked(controls(streams)...)
+}
+
+// isParallel 多个流合并后的流是否并行，跟随第一个流
+func isParallel[T any](streams []Stream[T]) bool {
+	return len(streams) > 0 && streams[0].isParallel
+}
+
+type mergeItem[T any] struct {
+	item  T
+	index int
+}
+
+type mergeHeap[T any] struct {
+	items []mergeItem[T]
+	cmp   func(T, T) int
+}
+
+func (h *mergeHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	c := h.cmp(h.items[i].item, h.items[j].item)
+	if c == 0 {
+		// 相等时按输入流的顺序输出，保证稳定
+		return h.items[i].index < h.items[j].index
+	}
+	return c < 0
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *mergeHeap[T]) Push(x any) {
+	h.items = append(h.items, x.(mergeItem[T]))
+}
+
+func (h *mergeHeap[T]) Pop() any {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[:n-1]
+	return x
+}
@@ -0,0 +1,32 @@
+package stream
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMergeSorted(t *testing.T) {
+	res := MergeSorted(intCmp, Of(1, 4, 7), Of(2, 5, 8, 9), Of[int](), Of(3, 6)).ToSlice()
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MergeSorted() = %v, want %v", res, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	res := Merge(Of(1, 2, 3), OfFrom(func(source chan<- int) {
+		source <- 4
+		source <- 5
+	}), Of(6)).ToSlice()
+	sort.Ints(res)
+	if want := []int{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(res, want) {
+		t.Errorf("Merge() = %v, want %v", res, want)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	res := Interleave(Of(1, 4, 6, 7), Of(2), Of(3, 5)).ToSlice()
+	if want := []int{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(res, want) {
+		t.Errorf("Interleave() = %v, want %v", res, want)
+	}
+}
@@ -0,0 +1,102 @@
+package stream
+
+import "context"
+
+// WithPrefetch Paginate 最多提前获取 n 页，默认为 1，即输出当前页的同时获取下一页
+func WithPrefetch(n int) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.prefetch = n
+	}
+}
+
+/*
+Paginate 把基于游标(token)分页的接口转换为惰性的扁平流
+第一次调用 fetch 的 token 为空字符串，fetch 返回的 next 为空时结束；下游结束(比如 Limit 已经满足)时不再获取新的页，
+并取消正在进行中的 fetch(通过 ctx)
+fetch 返回错误时流结束，错误可以在终止操作之后通过 Err() 获取；支持 WithContext、WithCallTimeout、WithPrefetch
+
+eg:
+
+	users, err := Paginate(func(ctx context.Context, token string) ([]User, string, error) {
+		resp, err := client.ListUsers(ctx, &ListUsersRequest{PageToken: token, PageSize: 100})
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Users, resp.NextPageToken, nil
+	}, WithPrefetch(2)).Filter(isActive).Limit(1000).ToSliceE()
+*/
+func Paginate[T any](fetch func(ctx context.Context, token string) (items []T, next string, err error), opts ...ConcurrentOption) Stream[T] {
+	o := concurrentOptions{ctx: context.Background(), prefetch: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.prefetch < 1 {
+		o.prefetch = 1
+	}
+	source := make(chan T)
+	res := Range(source, false)
+	ctx, cancel := context.WithCancel(o.ctx)
+	// 当前正在输出的页之外，缓冲中最多还有 prefetch-1 页，再加上正在获取的一页
+	pages := make(chan []T, o.prefetch-1)
+	GoSafe(func() {
+		defer close(pages)
+		token := ""
+		for {
+			callCtx, callCancel := ctx, context.CancelFunc(func() {})
+			if o.timeout > 0 {
+				callCtx, callCancel = context.WithTimeout(ctx, o.timeout)
+			}
+			items, next, err := safeCallPage(callCtx, token, fetch)
+			callCancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				res.ctl.fail(err)
+				return
+			}
+			select {
+			case pages <- items:
+			case <-ctx.Done():
+				return
+			}
+			if next == "" {
+				return
+			}
+			token = next
+		}
+	})
+	GoSafe(func() {
+		defer close(source)
+		defer cancel()
+		for page := range pages {
+			for _, item := range page {
+				select {
+				case source <- item:
+				case <-res.ctl.Done():
+					cancel()
+					go drain(pages)
+					return
+				}
+			}
+		}
+		// 外部的 ctx 被取消
+		if err := o.ctx.Err(); err != nil {
+			res.ctl.fail(err)
+		}
+	})
+	return res
+}
+
+// safeCallPage 调用 fetch，把 panic 转换为错误
+func safeCallPage[T any](ctx context.Context, token string, fetch func(context.Context, string) ([]T, string, error)) (items []T, next string, err error) {
+	type page struct {
+		items []T
+		next  string
+	}
+	p, err := safeCall(ctx, token, func(ctx context.Context, token string) (page, error) {
+		items, next, err := fetch(ctx, token)
+		return page{items: items, next: next}, err
+	})
+	return p.items, p.next, err
+}
@@ -0,0 +1,112 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// pagedServer 每页返回 size 个数字，共 pages 页
+func pagedServer(pages, size int, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("token"))
+		var resp struct {
+			Items []int  `json:"items"`
+			Next  string `json:"next"`
+		}
+		for i := 0; i < size; i++ {
+			resp.Items = append(resp.Items, page*size+i)
+		}
+		if page+1 < pages {
+			resp.Next = strconv.Itoa(page + 1)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func fetchPage(url string) func(ctx context.Context, token string) ([]int, string, error) {
+	return func(ctx context.Context, token string) ([]int, string, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"?token="+token, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		var page struct {
+			Items []int  `json:"items"`
+			Next  string `json:"next"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		return page.Items, page.Next, err
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	var calls atomic.Int32
+	srv := pagedServer(4, 3, &calls)
+	defer srv.Close()
+
+	res, err := Paginate(fetchPage(srv.URL), WithPrefetch(2)).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := IntRange(0, 12, 1).ToSlice(); !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("fetched %d pages, want 4", calls.Load())
+	}
+}
+
+func TestPaginateLimit(t *testing.T) {
+	var calls atomic.Int32
+	fetch := func(ctx context.Context, token string) ([]int, string, error) {
+		n := calls.Add(1)
+		return []int{int(n)}, "next", nil
+	}
+	if res := Paginate(fetch, WithPrefetch(3)).Limit(2).ToSlice(); !reflect.DeepEqual(res, []int{1, 2}) {
+		t.Fatalf("got %v", res)
+	}
+	// 已经输出的 2 页，最多 3 页的预取，以及取消之前可能已经开始的一次
+	if n := calls.Load(); n > 6 {
+		t.Fatalf("fetched %d pages after Limit", n)
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	boom := errors.New("boom")
+	fetch := func(ctx context.Context, token string) ([]int, string, error) {
+		if token == "2" {
+			return nil, "", boom
+		}
+		n, _ := strconv.Atoi(token)
+		return []int{n}, strconv.Itoa(n + 1), nil
+	}
+	res, err := Paginate(fetch).ToSliceE()
+	if !errors.Is(err, boom) || !reflect.DeepEqual(res, []int{0, 1}) {
+		t.Fatalf("got %v, err %v", res, err)
+	}
+}
+
+func TestPaginateContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fetch := func(ctx context.Context, token string) ([]int, string, error) {
+		if token == "1" {
+			cancel()
+			<-ctx.Done()
+			return nil, "", ctx.Err()
+		}
+		return []int{0}, "1", nil
+	}
+	res, err := Paginate(fetch, WithContext(ctx)).ToSliceE()
+	if !errors.Is(err, context.Canceled) || !reflect.DeepEqual(res, []int{0}) {
+		t.Fatalf("got %v, err %v", res, err)
+	}
+}
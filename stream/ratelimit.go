@@ -0,0 +1,142 @@
+package stream
+
+import "time"
+
+// RateLimit 令牌桶限流，每秒产生 perSecond 个令牌，桶中最多保存 burst 个令牌
+// 每个元素需要获取一个令牌才能继续向下游传递，令牌不足时等待，不会丢弃元素
+func (s Stream[T]) RateLimit(perSecond float64, burst int, opts ...TimeOption) Stream[T] {
+	if perSecond <= 0 {
+		panic("perSecond must be positive")
+	}
+	if burst <= 0 {
+		panic("burst must be positive")
+	}
+	clock := newTimeOptions(opts).clock
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		tokens := float64(burst)
+		last := clock.Now()
+		for item := range s.source {
+			now := clock.Now()
+			tokens += now.Sub(last).Seconds() * perSecond
+			if tokens > float64(burst) {
+				tokens = float64(burst)
+			}
+			last = now
+			if tokens < 1 {
+				wait := time.Duration((1 - tokens) / perSecond * float64(time.Second))
+				<-clock.After(wait)
+				now = clock.Now()
+				tokens += now.Sub(last).Seconds() * perSecond
+				last = now
+			}
+			tokens--
+			source <- item
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// Throttle 节流，输出一个元素之后的 d 时间内到达的元素都会被丢弃(每个时间间隔只保留第一个元素)
+func (s Stream[T]) Throttle(d time.Duration, opts ...TimeOption) Stream[T] {
+	clock := newTimeOptions(opts).clock
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		var last time.Time
+		emitted := false
+		for item := range s.source {
+			now := clock.Now()
+			if emitted && now.Sub(last) < d {
+				continue
+			}
+			emitted = true
+			last = now
+			source <- item
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// Debounce 防抖，元素到达后等待 d 时间，期间没有新的元素到达才输出，否则丢弃并重新等待
+// 即只输出每一段连续到达的元素中的最后一个，流结束时会立即输出还在等待的元素
+func (s Stream[T]) Debounce(d time.Duration, opts ...TimeOption) Stream[T] {
+	clock := newTimeOptions(opts).clock
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		var pending T
+		// 整个流复用同一个定时器，新元素到达时重置
+		var timer ClockTimer
+		var fired <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		for {
+			select {
+			case item, ok := <-s.source:
+				if !ok {
+					if fired != nil {
+						source <- pending
+					}
+					return
+				}
+				pending = item
+				if timer == nil {
+					timer = clock.NewTimer(d)
+				} else {
+					timer.Reset(d)
+				}
+				fired = timer.C()
+			case <-fired:
+				fired = nil
+				source <- pending
+			}
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// Sample 采样，每隔 d 时间输出这段时间内到达的最后一个元素，没有新元素时不输出
+// 流结束时会立即输出还未输出的最后一个元素
+func (s Stream[T]) Sample(d time.Duration, opts ...TimeOption) Stream[T] {
+	if d <= 0 {
+		panic("d must be positive")
+	}
+	clock := newTimeOptions(opts).clock
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		var latest T
+		hasLatest := false
+		tick := clock.NewTimer(d)
+		defer tick.Stop()
+		for {
+			select {
+			case item, ok := <-s.source:
+				if !ok {
+					if hasLatest {
+						source <- latest
+					}
+					return
+				}
+				latest = item
+				hasLatest = true
+			case <-tick.C():
+				tick.Reset(d)
+				if hasLatest {
+					hasLatest = false
+					source <- latest
+				}
+			}
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// scriptedClock 每次调用 Now 依次返回预先设定的时间
+type scriptedClock struct {
+	*FakeClock
+	times []time.Time
+}
+
+func (c *scriptedClock) Now() time.Time {
+	now := c.times[0]
+	c.times = c.times[1:]
+	return now
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	out := Of(1, 2, 3, 4).RateLimit(1, 2, WithClock(clock)).source
+	// 桶中初始有 2 个令牌
+	if a, b := <-out, <-out; a != 1 || b != 2 {
+		t.Fatalf("got %d %d, want 1 2", a, b)
+	}
+	for _, want := range []int{3, 4} {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		if got := <-out; got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("stream should be closed")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	base := time.Unix(0, 0)
+	clock := &scriptedClock{FakeClock: NewFakeClock(base)}
+	for _, ms := range []int{0, 300, 900, 1000, 1500, 2100} {
+		clock.times = append(clock.times, base.Add(time.Duration(ms)*time.Millisecond))
+	}
+	res := Of(1, 2, 3, 4, 5, 6).Throttle(time.Second, WithClock(clock)).ToSlice()
+	if want := []int{1, 4, 6}; !reflect.DeepEqual(res, want) {
+		t.Errorf("Throttle() = %v, want %v", res, want)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	in := make(chan int)
+	out := Range(in, false).Debounce(time.Second, WithClock(clock)).source
+
+	in <- 1
+	clock.BlockUntil(1)
+	clock.Advance(500 * time.Millisecond)
+	// 第二个 2 被接收时第一个 2 已经重置了定时器，两次重置的到期时间相同
+	in <- 2
+	in <- 2
+	if n := clock.waiting(); n != 1 {
+		t.Fatalf("%d timers pending, want 1 reused timer", n)
+	}
+	clock.Advance(time.Second)
+	if got := <-out; got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+	in <- 3
+	close(in)
+	if got := <-out; got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("stream should be closed")
+	}
+	if n := clock.waiting(); n != 0 {
+		t.Fatalf("%d timers pending after the stream ended, want 0", n)
+	}
+}
+
+func TestSample(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	in := make(chan int)
+	out := Range(in, false).Sample(time.Second, WithClock(clock)).source
+
+	clock.BlockUntil(1)
+	in <- 1
+	in <- 2
+	clock.Advance(time.Second)
+	if got := <-out; got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	in <- 3
+	close(in)
+	if got := <-out; got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("stream should be closed")
+	}
+}
@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试的次数(包括第一次)，小于等于 0 时只尝试一次
+	MaxAttempts int
+	// Backoff 第 attempt 次(从 1 开始)失败后到下一次重试之间的等待时间，为 nil 时立即重试
+	Backoff func(attempt int) time.Duration
+	// Jitter 等待时间的随机抖动比例，取值 [0,1]，实际等待时间在 backoff*(1±Jitter) 之间
+	Jitter float64
+	// RetryIf 判断错误是否需要重试，为 nil 时所有错误都重试
+	RetryIf func(err error) bool
+	// Clock 等待使用的时钟，为 nil 时使用 SystemClock
+	Clock Clock
+}
+
+// ConstantBackoff 每次重试前等待固定的时间
+func ConstantBackoff(d time.Duration) func(attempt int) time.Duration {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff 指数退避，等待时间从 base 开始每次翻倍，最多为 max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// Failed 重试之后仍然失败的元素
+type Failed[T any] struct {
+	Item     T
+	Err      error
+	Attempts int
+}
+
+// RetryOption 设置 MapRetry 失败元素的去向(死信)
+type RetryOption[T any] func(*retryOptions[T])
+
+type retryOptions[T any] struct {
+	deadLetter func(Failed[T])
+}
+
+// WithDeadLetter 失败的元素交给 fn 处理，fn 可能被多个协程同时调用(并行流)
+func WithDeadLetter[T any](fn func(Failed[T])) RetryOption[T] {
+	return func(o *retryOptions[T]) {
+		o.deadLetter = fn
+	}
+}
+
+// WithDeadLetterChan 失败的元素发送到 ch，ch 需要被及时消费，否则会阻塞流的处理
+func WithDeadLetterChan[T any](ch chan<- Failed[T]) RetryOption[T] {
+	return func(o *retryOptions[T]) {
+		o.deadLetter = func(f Failed[T]) {
+			ch <- f
+		}
+	}
+}
+
+/*
+MapRetry 带重试的转换，fn 返回错误时按 policy 重试，fn 中的 panic 也会被当作错误
+重试之后仍然失败的元素交给死信(WithDeadLetter/WithDeadLetterChan)处理，
+没有设置死信时失败的元素被丢弃，第一个错误可以在终止操作之后通过 Err() 获取
+
+eg:
+
+	res := MapRetry(Of(records...), func(r Record) (Row, error) {
+		return load(r)
+	}, RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     ExponentialBackoff(100*time.Millisecond, time.Second),
+		Jitter:      0.2,
+	}, WithDeadLetter(func(f Failed[Record]) {
+		log.Printf("record %v failed after %d attempts: %v", f.Item, f.Attempts, f.Err)
+	})).ToSlice()
+*/
+func MapRetry[T any, R any](s Stream[T], fn func(T) (R, error), policy RetryPolicy, opts ...RetryOption[T]) Stream[R] {
+	o := retryOptions[T]{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctl := newControl()
+	return walk(s, func(item T, pipe chan<- R) {
+		r, failed := retry(item, fn, policy)
+		if failed == nil {
+			pipe <- r
+			return
+		}
+		if o.deadLetter == nil {
+			ctl.fail(failed.Err)
+			return
+		}
+		o.deadLetter(*failed)
+	}).linked(ctl)
+}
+
+// MapRetryWithFailures 同 MapRetry，重试之后仍然失败的元素输出到第二个流中
+// 两个流需要同时被消费(比如在不同的协程中)，否则会相互阻塞
+func MapRetryWithFailures[T any, R any](s Stream[T], fn func(T) (R, error), policy RetryPolicy) (Stream[R], Stream[Failed[T]]) {
+	failures := make(chan Failed[T])
+	res := MapRetry(s, fn, policy, WithDeadLetterChan[T](failures))
+	out := make(chan R)
+	// 结果流结束之后再关闭失败流
+	GoSafe(func() {
+		defer close(failures)
+		defer close(out)
+		for item := range res.source {
+			out <- item
+		}
+	})
+	return Range(out, res.isParallel).linked(res.ctl), Range(failures, res.isParallel).linked(s.ctl)
+}
+
+func retry[T any, R any](item T, fn func(T) (R, error), policy RetryPolicy) (R, *Failed[T]) {
+	clock := policy.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	var r R
+	var err error
+	// 同一个元素的多次重试复用一个定时器
+	var timer ClockTimer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	attempt := 1
+	for ; ; attempt++ {
+		if r, err = safeCallE(item, fn); err == nil {
+			return r, nil
+		}
+		if attempt >= policy.MaxAttempts || (policy.RetryIf != nil && !policy.RetryIf(err)) {
+			break
+		}
+		if policy.Backoff == nil {
+			continue
+		}
+		wait := jitter(policy.Backoff(attempt), policy.Jitter)
+		if timer == nil {
+			timer = clock.NewTimer(wait)
+		} else {
+			timer.Reset(wait)
+		}
+		<-timer.C()
+	}
+	return r, &Failed[T]{Item: item, Err: err, Attempts: attempt}
+}
+
+func jitter(d time.Duration, factor float64) time.Duration {
+	if factor <= 0 || d <= 0 {
+		return d
+	}
+	if factor > 1 {
+		factor = 1
+	}
+	return time.Duration(float64(d) * (1 + factor*(2*rand.Float64()-1)))
+}
@@ -0,0 +1,100 @@
+package stream
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+var errPermanent = errors.New("permanent")
+
+// flaky 前 failures[item] 次调用返回 errTransient，item 为负数时总是返回 errPermanent
+func flaky(failures map[int]int) func(int) (int, error) {
+	return func(item int) (int, error) {
+		if item < 0 {
+			return 0, errPermanent
+		}
+		if failures[item] > 0 {
+			failures[item]--
+			return 0, errTransient
+		}
+		return item * 10, nil
+	}
+}
+
+func TestMapRetry(t *testing.T) {
+	var failed []Failed[int]
+	s := MapRetry(Of(1, 2, -3, 4), flaky(map[int]int{1: 2, 4: 5}), RetryPolicy{
+		MaxAttempts: 3,
+		RetryIf: func(err error) bool {
+			return errors.Is(err, errTransient)
+		},
+	}, WithDeadLetter(func(f Failed[int]) {
+		failed = append(failed, f)
+	}))
+	res := s.ToSlice()
+	if want := []int{10, 20}; !reflect.DeepEqual(res, want) {
+		t.Errorf("MapRetry() = %v, want %v", res, want)
+	}
+	want := []Failed[int]{{Item: -3, Err: errPermanent, Attempts: 1}, {Item: 4, Err: errTransient, Attempts: 3}}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("dead letters = %v, want %v", failed, want)
+	}
+	if s.Err() != nil {
+		t.Errorf("Err() = %v, want nil when a dead letter is set", s.Err())
+	}
+}
+
+func TestMapRetryWithoutDeadLetter(t *testing.T) {
+	s := MapRetry(Of(1, -2), flaky(nil), RetryPolicy{MaxAttempts: 2})
+	if res := s.ToSlice(); !reflect.DeepEqual(res, []int{10}) {
+		t.Errorf("MapRetry() = %v, want [10]", res)
+	}
+	if !errors.Is(s.Err(), errPermanent) {
+		t.Errorf("Err() = %v, want %v", s.Err(), errPermanent)
+	}
+}
+
+func TestMapRetryWithFailures(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	res, failures := MapRetryWithFailures(Of(1, -2, 3), flaky(map[int]int{3: 1}), RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     ExponentialBackoff(time.Second, time.Minute),
+		Clock:       clock,
+	})
+	failedCh := make(chan []Failed[int])
+	go func() {
+		failedCh <- failures.ToSlice()
+	}()
+	go func() {
+		// -2 和 3 各需要等待一次退避
+		for i := 0; i < 2; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+		}
+	}()
+	items := res.ToSlice()
+	sort.Ints(items)
+	if want := []int{10, 30}; !reflect.DeepEqual(items, want) {
+		t.Errorf("MapRetryWithFailures() = %v, want %v", items, want)
+	}
+	if failed := <-failedCh; len(failed) != 1 || failed[0].Item != -2 || failed[0].Attempts != 2 {
+		t.Errorf("failures = %v", failed)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	var res []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		res = append(res, backoff(attempt))
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("ExponentialBackoff() = %v, want %v", res, want)
+	}
+}
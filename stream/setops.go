@@ -0,0 +1,168 @@
+package stream
+
+// 集合运算，元素通过 keyFn 计算出的 key 判断是否相同，结果中的 key 不重复(集合语义)
+// Union/Intersect/Except/SymmetricDifference 基于哈希实现，输入可以无序；
+// 带 Sorted 后缀的版本基于归并实现，要求两个输入都已经按 cmp 升序排列，只需要常量内存，并且输出同样有序
+
+// Union 并集，先输出 a 中的元素，再输出 b 中 key 未出现过的元素
+func Union[T any, K comparable](a, b Stream[T], keyFn func(T) K) Stream[T] {
+	return Concat(a, b).Distinct(func(item T) any {
+		return keyFn(item)
+	})
+}
+
+// Intersect 交集，输出 a 中 key 同时存在于 b 的元素，会先把 b 的 key 全部读入内存
+func Intersect[T any, K comparable](a, b Stream[T], keyFn func(T) K) Stream[T] {
+	return filterByKeys(a, b, keyFn, true)
+}
+
+// Except 差集，输出 a 中 key 不存在于 b 的元素，会先把 b 的 key 全部读入内存
+func Except[T any, K comparable](a, b Stream[T], keyFn func(T) K) Stream[T] {
+	return filterByKeys(a, b, keyFn, false)
+}
+
+// SymmetricDifference 对称差集，先输出 a 中 key 不存在于 b 的元素，再输出 b 中 key 不存在于 a 的元素
+func SymmetricDifference[T any, K comparable](a, b Stream[T], keyFn func(T) K) Stream[T] {
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		bKeys := make(map[K]struct{})
+		var bItems []T
+		for item := range b.source {
+			key := keyFn(item)
+			if _, ok := bKeys[key]; !ok {
+				bKeys[key] = struct{}{}
+				bItems = append(bItems, item)
+			}
+		}
+		aKeys := make(map[K]struct{})
+		for item := range a.source {
+			key := keyFn(item)
+			if _, ok := aKeys[key]; ok {
+				continue
+			}
+			aKeys[key] = struct{}{}
+			if _, ok := bKeys[key]; !ok {
+				source <- item
+			}
+		}
+		for _, item := range bItems {
+			if _, ok := aKeys[keyFn(item)]; !ok {
+				source <- item
+			}
+		}
+	})
+	return Range(source, a.isParallel).linked(a.ctl, b.ctl)
+}
+
+func filterByKeys[T any, K comparable](a, b Stream[T], keyFn func(T) K, keep bool) Stream[T] {
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		bKeys := make(map[K]struct{})
+		for item := range b.source {
+			bKeys[keyFn(item)] = struct{}{}
+		}
+		emitted := make(map[K]struct{})
+		for item := range a.source {
+			key := keyFn(item)
+			if _, ok := emitted[key]; ok {
+				continue
+			}
+			if _, ok := bKeys[key]; ok == keep {
+				emitted[key] = struct{}{}
+				source <- item
+			}
+		}
+	})
+	return Range(source, a.isParallel).linked(a.ctl, b.ctl)
+}
+
+// UnionSorted 有序输入的并集，相等的元素只输出一次(优先输出 a 中的元素)
+func UnionSorted[T any](a, b Stream[T], cmp func(T, T) int) Stream[T] {
+	return mergeSets(a, b, cmp, true, true, true)
+}
+
+// IntersectSorted 有序输入的交集，输出 a 中的元素
+func IntersectSorted[T any](a, b Stream[T], cmp func(T, T) int) Stream[T] {
+	return mergeSets(a, b, cmp, false, true, false)
+}
+
+// ExceptSorted 有序输入的差集
+func ExceptSorted[T any](a, b Stream[T], cmp func(T, T) int) Stream[T] {
+	return mergeSets(a, b, cmp, true, false, false)
+}
+
+// SymmetricDifferenceSorted 有序输入的对称差集
+func SymmetricDifferenceSorted[T any](a, b Stream[T], cmp func(T, T) int) Stream[T] {
+	return mergeSets(a, b, cmp, true, false, true)
+}
+
+// sortedCursor 顺序读取有序流，并跳过与上一个元素相等的元素
+type sortedCursor[T any] struct {
+	source <-chan T
+	cmp    func(T, T) int
+	cur    T
+	ok     bool
+}
+
+func newSortedCursor[T any](source <-chan T, cmp func(T, T) int) *sortedCursor[T] {
+	c := &sortedCursor[T]{source: source, cmp: cmp}
+	c.cur, c.ok = <-source
+	return c
+}
+
+func (c *sortedCursor[T]) next() {
+	prev := c.cur
+	for {
+		c.cur, c.ok = <-c.source
+		if !c.ok || c.cmp(prev, c.cur) != 0 {
+			return
+		}
+	}
+}
+
+// mergeSets 归并两个有序流，onlyA/both/onlyB 分别表示是否输出只在 a 中、两边都有、只在 b 中的元素
+func mergeSets[T any](a, b Stream[T], cmp func(T, T) int, onlyA, both, onlyB bool) Stream[T] {
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+
+		ca := newSortedCursor(a.source, cmp)
+		cb := newSortedCursor(b.source, cmp)
+		defer func() {
+			go drain(a.source)
+			go drain(b.source)
+		}()
+		for ca.ok && cb.ok {
+			c := cmp(ca.cur, cb.cur)
+			switch {
+			case c < 0:
+				if onlyA {
+					source <- ca.cur
+				}
+				ca.next()
+			case c > 0:
+				if onlyB {
+					source <- cb.cur
+				}
+				cb.next()
+			default:
+				if both {
+					source <- ca.cur
+				}
+				ca.next()
+				cb.next()
+			}
+		}
+		for ; ca.ok && onlyA; ca.next() {
+			source <- ca.cur
+		}
+		for ; cb.ok && onlyB; cb.next() {
+			source <- cb.cur
+		}
+	})
+	return Range(source, a.isParallel).linked(a.ctl, b.ctl)
+}
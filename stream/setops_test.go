@@ -0,0 +1,39 @@
+package stream
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intKey(item int) int {
+	return item
+}
+
+func intCmp(a, b int) int {
+	return a - b
+}
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(a, b Stream[int]) Stream[int]
+		want []int
+	}{
+		{"Union", func(a, b Stream[int]) Stream[int] { return Union(a, b, intKey) }, []int{1, 2, 3, 4, 5}},
+		{"Intersect", func(a, b Stream[int]) Stream[int] { return Intersect(a, b, intKey) }, []int{2, 3}},
+		{"Except", func(a, b Stream[int]) Stream[int] { return Except(a, b, intKey) }, []int{1}},
+		{"SymmetricDifference", func(a, b Stream[int]) Stream[int] { return SymmetricDifference(a, b, intKey) }, []int{1, 4, 5}},
+		{"UnionSorted", func(a, b Stream[int]) Stream[int] { return UnionSorted(a, b, intCmp) }, []int{1, 2, 3, 4, 5}},
+		{"IntersectSorted", func(a, b Stream[int]) Stream[int] { return IntersectSorted(a, b, intCmp) }, []int{2, 3}},
+		{"ExceptSorted", func(a, b Stream[int]) Stream[int] { return ExceptSorted(a, b, intCmp) }, []int{1}},
+		{"SymmetricDifferenceSorted", func(a, b Stream[int]) Stream[int] { return SymmetricDifferenceSorted(a, b, intCmp) }, []int{1, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := tt.fn(Of(1, 2, 2, 3), Of(2, 3, 3, 4, 5)).ToSlice()
+			if !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("%s() = %v, want %v", tt.name, res, tt.want)
+			}
+		})
+	}
+}
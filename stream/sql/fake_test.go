@@ -0,0 +1,144 @@
+package sql
+
+import (
+	gosql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeDB 测试用的内存数据库，所有查询都返回 columns/rows，Exec 的参数在事务提交之后记录到 execs
+type fakeDB struct {
+	mu         sync.Mutex
+	columns    []string
+	rows       [][]driver.Value
+	execs      [][]driver.Value
+	failExec   func(args []driver.Value) error
+	closedRows int
+	commits    int
+	rollbacks  int
+}
+
+var fakeDBs sync.Map
+
+type fakeDriver struct{}
+
+func init() {
+	gosql.Register("streamfake", fakeDriver{})
+}
+
+// openFakeDB 打开一个使用 db 作为数据的 *sql.DB
+func openFakeDB(t testing.TB, db *fakeDB) *gosql.DB {
+	fakeDBs.Store(t.Name(), db)
+	conn, err := gosql.Open("streamfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		fakeDBs.Delete(t.Name())
+	})
+	return conn
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	db, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, errors.New("fake: unknown database " + name)
+	}
+	return &fakeConn{db: db.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+	db      *fakeDB
+	pending [][]driver.Value
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = nil
+	return &fakeTx{conn: c}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx *fakeTx) Commit() error {
+	db := tx.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.execs = append(db.execs, tx.conn.pending...)
+	db.commits++
+	tx.conn.pending = nil
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	db := tx.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rollbacks++
+	tx.conn.pending = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn *fakeConn
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if f := s.conn.db.failExec; f != nil {
+		if err := f(args); err != nil {
+			return nil, err
+		}
+	}
+	s.conn.pending = append(s.conn.pending, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{db: s.conn.db}, nil
+}
+
+type fakeRows struct {
+	db  *fakeDB
+	pos int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.db.columns
+}
+
+func (r *fakeRows) Close() error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	r.db.closedRows++
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.db.rows) {
+		return io.EOF
+	}
+	copy(dest, r.db.rows[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Package sql 提供从 database/sql 的查询结果创建流，以及把流批量写入数据库的方法
+
+eg:
+
+	rows, err := db.QueryContext(ctx, "SELECT id, name FROM users")
+	if err != nil {
+		return err
+	}
+	users, err := sql.FromRowsStruct[User](rows).Filter(isActive).ToSliceE()
+*/
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+/*
+FromRows 通过 scanFn 把查询结果的每一行转换为 T
+rows 在流结束或者下游结束(比如 Limit 已经满足)时关闭，scanFn 和 rows 的错误可以在终止操作之后通过 Err() 获取
+
+eg:
+
+	names := sql.FromRows(rows, func(rows *gosql.Rows) (string, error) {
+		var name string
+		err := rows.Scan(&name)
+		return name, err
+	}).Limit(10).ToSlice()
+*/
+func FromRows[T any](rows *gosql.Rows, scanFn func(rows *gosql.Rows) (T, error)) stream.Stream[T] {
+	return stream.OfFromE(func(emit func(T) bool) error {
+		defer rows.Close()
+		for rows.Next() {
+			item, err := scanFn(rows)
+			if err != nil {
+				return err
+			}
+			if !emit(item) {
+				return nil
+			}
+		}
+		return rows.Err()
+	})
+}
+
+/*
+FromRowsStruct 把查询结果的每一行转换为结构体 T，列名通过字段的 db tag 指定，
+没有 tag 时和字段名按不区分大小写的方式匹配，tag 为 "-" 的字段被忽略，没有对应字段的列被丢弃
+字段的类型需要能够被 rows.Scan 接收，比如 int64、string、time.Time、sql.NullString 以及实现了 sql.Scanner 的类型
+
+eg:
+
+	type User struct {
+		ID        int64            `db:"id"`
+		Name      string           `db:"name"`
+		Email     gosql.NullString `db:"email"`
+		CreatedAt time.Time        `db:"created_at"`
+	}
+	users, err := sql.FromRowsStruct[User](rows).ToSliceE()
+*/
+func FromRowsStruct[T any](rows *gosql.Rows) stream.Stream[T] {
+	var fields [][]int
+	return FromRows(rows, func(rows *gosql.Rows) (T, error) {
+		var item T
+		if fields == nil {
+			columns, err := rows.Columns()
+			if err != nil {
+				return item, err
+			}
+			if fields, err = columnFields(reflect.TypeOf(item), columns); err != nil {
+				return item, err
+			}
+		}
+		v := reflect.ValueOf(&item).Elem()
+		dest := make([]any, len(fields))
+		for i, index := range fields {
+			if index == nil {
+				dest[i] = new(any)
+				continue
+			}
+			dest[i] = v.FieldByIndex(index).Addr().Interface()
+		}
+		return item, rows.Scan(dest...)
+	})
+}
+
+// columnFields 返回每一列对应的字段，nil 表示没有对应的字段
+func columnFields(t reflect.Type, columns []string) ([][]int, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("stream/sql: type %s is not a struct", t)
+	}
+	byName := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("db")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		byName[name] = f.Index
+	}
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := byName[column]
+		if !ok {
+			index = byName[strings.ToLower(column)]
+		}
+		fields[i] = index
+	}
+	return fields, nil
+}
+
+// TxBeginner 可以开始事务的数据库连接，比如 *sql.DB、*sql.Conn
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *gosql.TxOptions) (*gosql.Tx, error)
+}
+
+/*
+BatchExec 把流中的元素分批写入数据库：每 batchSize 个元素在一个事务中通过同一个预编译的 stmt 执行，参数为 argsFn 的返回值
+某一批失败时回滚该批并停止消费流，返回已经提交的元素个数和错误
+
+eg:
+
+	n, err := sql.BatchExec(ctx, users, db, "INSERT INTO users(id, name) VALUES (?, ?)", 500, func(u User) []any {
+		return []any{u.ID, u.Name}
+	})
+*/
+func BatchExec[T any](ctx context.Context, s stream.Stream[T], db TxBeginner, stmt string, batchSize int,
+	argsFn func(item T) []any) (int64, error) {
+	if batchSize <= 0 {
+		panic("batchSize must be positive")
+	}
+	var committed int64
+	var err error
+	batch := make([]T, 0, batchSize)
+	flush := func() {
+		if err = execBatch(ctx, db, stmt, batch, argsFn); err == nil {
+			committed += int64(len(batch))
+		}
+		batch = batch[:0]
+	}
+	s.ForEachWhile(func(item T) bool {
+		batch = append(batch, item)
+		if len(batch) == batchSize {
+			flush()
+		}
+		return err == nil
+	})
+	if err == nil && len(batch) > 0 {
+		flush()
+	}
+	if err == nil {
+		err = s.Err()
+	}
+	return committed, err
+}
+
+func execBatch[T any](ctx context.Context, db TxBeginner, stmt string, batch []T, argsFn func(item T) []any) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	prepared, err := tx.PrepareContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	defer prepared.Close()
+	for _, item := range batch {
+		if _, err = prepared.ExecContext(ctx, argsFn(item)...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
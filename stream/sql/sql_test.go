@@ -0,0 +1,154 @@
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/todocoder/go-stream/stream"
+)
+
+type user struct {
+	ID        int64            `db:"id"`
+	Name      string           `db:"user_name"`
+	Email     gosql.NullString `db:"email"`
+	CreatedAt time.Time
+	Ignored   string `db:"-"`
+}
+
+func usersDB() *fakeDB {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &fakeDB{
+		columns: []string{"id", "user_name", "email", "CREATEDAT", "extra"},
+		rows: [][]driver.Value{
+			{int64(1), "alice", "a@example.com", created, "x"},
+			{int64(2), "bob", nil, created, "y"},
+			{int64(3), "carol", "c@example.com", created, "z"},
+		},
+	}
+}
+
+func TestFromRows(t *testing.T) {
+	fake := usersDB()
+	db := openFakeDB(t, fake)
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := FromRows(rows, func(rows *gosql.Rows) (string, error) {
+		var id int64
+		var name, email, created, extra any
+		err := rows.Scan(&id, &name, &email, &created, &extra)
+		return name.(string), err
+	}).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice", "bob", "carol"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	if fake.closedRows != 1 {
+		t.Fatalf("rows closed %d times, want 1", fake.closedRows)
+	}
+}
+
+func TestFromRowsStruct(t *testing.T) {
+	fake := usersDB()
+	db := openFakeDB(t, fake)
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := FromRowsStruct[user](rows).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := []user{
+		{ID: 1, Name: "alice", Email: gosql.NullString{String: "a@example.com", Valid: true}, CreatedAt: created},
+		{ID: 2, Name: "bob", CreatedAt: created},
+		{ID: 3, Name: "carol", Email: gosql.NullString{String: "c@example.com", Valid: true}, CreatedAt: created},
+	}
+	if !reflect.DeepEqual(users, want) {
+		t.Fatalf("got %+v, want %+v", users, want)
+	}
+}
+
+func TestFromRowsStructLimit(t *testing.T) {
+	fake := usersDB()
+	db := openFakeDB(t, fake)
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users := FromRowsStruct[user](rows).Limit(1).ToSlice(); len(users) != 1 {
+		t.Fatalf("got %+v", users)
+	}
+	// 下游结束之后 rows 被关闭
+	deadline := time.Now().Add(time.Second)
+	for {
+		fake.mu.Lock()
+		closed := fake.closedRows
+		fake.mu.Unlock()
+		if closed == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("rows not closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFromRowsScanError(t *testing.T) {
+	db := openFakeDB(t, usersDB())
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type bad struct {
+		ID   int64 `db:"id"`
+		Name int64 `db:"user_name"`
+	}
+	if _, err := FromRowsStruct[bad](rows).ToSliceE(); err == nil {
+		t.Fatal("want scan error")
+	}
+}
+
+func TestBatchExec(t *testing.T) {
+	fake := &fakeDB{}
+	db := openFakeDB(t, fake)
+	n, err := BatchExec(context.Background(), stream.IntRange(0, 7, 1), db, "INSERT", 3, func(i int) []any {
+		return []any{i, "name"}
+	})
+	if err != nil || n != 7 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if fake.commits != 3 || len(fake.execs) != 7 || fake.execs[6][0] != int64(6) {
+		t.Fatalf("commits %d, execs %v", fake.commits, fake.execs)
+	}
+}
+
+func TestBatchExecError(t *testing.T) {
+	fake := &fakeDB{failExec: func(args []driver.Value) error {
+		if args[0] == int64(4) {
+			return errors.New("constraint violation")
+		}
+		return nil
+	}}
+	db := openFakeDB(t, fake)
+	n, err := BatchExec(context.Background(), stream.IntRange(0, 100, 1), db, "INSERT", 3, func(i int) []any {
+		return []any{i}
+	})
+	if err == nil || err.Error() != "constraint violation" {
+		t.Fatalf("err = %v", err)
+	}
+	// 第二批(3,4,5)被回滚
+	if n != 3 || len(fake.execs) != 3 || fake.commits != 1 || fake.rollbacks != 1 {
+		t.Fatalf("n = %d, execs %v, commits %d, rollbacks %d", n, fake.execs, fake.commits, fake.rollbacks)
+	}
+}
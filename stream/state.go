@@ -0,0 +1,186 @@
+package stream
+
+import (
+	"container/list"
+	"time"
+)
+
+// StateOption 设置 MapWithState、MapWithStateByKey 的选项
+type StateOption[K comparable, S any, R any] func(*stateOptions[K, S, R])
+
+type stateOptions[K comparable, S any, R any] struct {
+	ttl     time.Duration
+	onEvict func(key K, state S) (R, bool)
+}
+
+func newStateOptions[K comparable, S any, R any](opts []StateOption[K, S, R]) stateOptions[K, S, R] {
+	var o stateOptions[K, S, R]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithStateTTL 超过 ttl 没有新元素的 key 会被淘汰，状态被丢弃，默认不淘汰
+func WithStateTTL[K comparable, S any, R any](ttl time.Duration) StateOption[K, S, R] {
+	return func(o *stateOptions[K, S, R]) {
+		o.ttl = ttl
+	}
+}
+
+// WithOnEvict key 被淘汰(TTL 过期或流结束)时调用 fn，返回 true 时输出返回值作为该 key 的最终结果
+func WithOnEvict[K comparable, S any, R any](fn func(key K, state S) (R, bool)) StateOption[K, S, R] {
+	return func(o *stateOptions[K, S, R]) {
+		o.onEvict = fn
+	}
+}
+
+/*
+MapWithState 带状态的按 key 转换，每个 key 拥有一份独立的状态，第一次出现时通过 initState 初始化
+fn 可以修改状态，返回 true 时输出返回值，返回 false 时不输出
+流结束时所有还存在的 key 都会被淘汰并触发 WithOnEvict 设置的回调，可以用来输出会话等的最终结果
+状态只在一个协程中访问，fn 中不需要加锁
+
+eg: 统计每个用户的累计金额
+
+	res := MapWithState(Of(orders...), func(o Order) string {
+		return o.user
+	}, func(user string) int {
+		return 0
+	}, func(total *int, o Order) (string, bool) {
+		*total += o.amount
+		return fmt.Sprintf("%s:%d", o.user, *total), true
+	}).ToSlice()
+*/
+func MapWithState[T any, K comparable, S any, R any](s Stream[T], keyFn func(T) K, initState func(K) S,
+	fn func(*S, T) (R, bool), opts ...StateOption[K, S, R]) Stream[R] {
+	o := newStateOptions(opts)
+	source := make(chan R)
+	GoSafe(func() {
+		defer close(source)
+		newStateStore(keyFn, initState, fn, o, source).run(s.source)
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// MapWithStateByKey 按 key 分片到 workers 个协程中并行执行 MapWithState，相同 key 的元素按顺序处理，
+// 每个协程维护自己分片的状态，同样不需要加锁
+func MapWithStateByKey[T any, K comparable, S any, R any](s Stream[T], keyFn func(T) K, workers int, initState func(K) S,
+	fn func(*S, T) (R, bool), opts ...StateOption[K, S, R]) Stream[R] {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	o := newStateOptions(opts)
+	source := make(chan R, workers)
+	GoSafe(func() {
+		defer close(source)
+		shard(s.source, func(item T) uint64 {
+			return hashKey(keyFn(item))
+		}, workers, func(in <-chan T) {
+			newStateStore(keyFn, initState, fn, o, source).run(in)
+		})
+	})
+	return Range(source, true).linked(s.ctl)
+}
+
+type stateEntry[K comparable, S any] struct {
+	key      K
+	state    S
+	lastSeen time.Time
+}
+
+// stateStore 单个协程中的状态表，order 按最后出现的时间从旧到新排列
+type stateStore[T any, K comparable, S any, R any] struct {
+	keyFn     func(T) K
+	initState func(K) S
+	fn        func(*S, T) (R, bool)
+	opts      stateOptions[K, S, R]
+	out       chan<- R
+	now       func() time.Time
+	states    map[K]*list.Element
+	order     *list.List
+}
+
+func newStateStore[T any, K comparable, S any, R any](keyFn func(T) K, initState func(K) S, fn func(*S, T) (R, bool),
+	opts stateOptions[K, S, R], out chan<- R) *stateStore[T, K, S, R] {
+	return &stateStore[T, K, S, R]{
+		keyFn:     keyFn,
+		initState: initState,
+		fn:        fn,
+		opts:      opts,
+		out:       out,
+		now:       time.Now,
+		states:    make(map[K]*list.Element),
+		order:     list.New(),
+	}
+}
+
+func (st *stateStore[T, K, S, R]) run(in <-chan T) {
+	defer st.evictAll()
+	if st.opts.ttl <= 0 {
+		for item := range in {
+			st.process(item)
+		}
+		return
+	}
+	// 没有新元素时也定时清理过期的 key
+	ticker := time.NewTicker(st.opts.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case item, ok := <-in:
+			if !ok {
+				return
+			}
+			st.evictExpired()
+			st.process(item)
+		case <-ticker.C:
+			st.evictExpired()
+		}
+	}
+}
+
+func (st *stateStore[T, K, S, R]) process(item T) {
+	key := st.keyFn(item)
+	e, ok := st.states[key]
+	if ok {
+		st.order.MoveToBack(e)
+	} else {
+		e = st.order.PushBack(&stateEntry[K, S]{key: key, state: st.initState(key)})
+		st.states[key] = e
+	}
+	entry := e.Value.(*stateEntry[K, S])
+	if st.opts.ttl > 0 {
+		entry.lastSeen = st.now()
+	}
+	if r, ok := st.fn(&entry.state, item); ok {
+		st.out <- r
+	}
+}
+
+func (st *stateStore[T, K, S, R]) evictExpired() {
+	t := st.now()
+	for e := st.order.Front(); e != nil; e = st.order.Front() {
+		if t.Sub(e.Value.(*stateEntry[K, S]).lastSeen) < st.opts.ttl {
+			return
+		}
+		st.evict(e)
+	}
+}
+
+func (st *stateStore[T, K, S, R]) evictAll() {
+	for e := st.order.Front(); e != nil; e = st.order.Front() {
+		st.evict(e)
+	}
+}
+
+func (st *stateStore[T, K, S, R]) evict(e *list.Element) {
+	entry := st.order.Remove(e).(*stateEntry[K, S])
+	delete(st.states, entry.key)
+	if st.opts.onEvict == nil {
+		return
+	}
+	if r, ok := st.opts.onEvict(entry.key, entry.state); ok {
+		st.out <- r
+	}
+}
@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+type stateOrder struct {
+	user   string
+	amount int
+}
+
+func TestMapWithState(t *testing.T) {
+	orders := []stateOrder{{"tom", 1}, {"jerry", 2}, {"tom", 3}, {"jerry", 4}, {"tom", 5}}
+	running := func(total *int, o stateOrder) (string, bool) {
+		*total += o.amount
+		return fmt.Sprintf("%s:%d", o.user, *total), true
+	}
+	res := MapWithState(Of(orders...), func(o stateOrder) string {
+		return o.user
+	}, func(user string) int {
+		return 0
+	}, running, WithOnEvict(func(user string, total int) (string, bool) {
+		return fmt.Sprintf("%s=%d", user, total), true
+	})).ToSlice()
+	want := []string{"tom:1", "jerry:2", "tom:4", "jerry:6", "tom:9", "jerry=6", "tom=9"}
+	sort.Strings(res[5:])
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("MapWithState() = %v, want %v", res, want)
+	}
+
+	byKey := MapWithStateByKey(Of(orders...), func(o stateOrder) string {
+		return o.user
+	}, 2, func(user string) int {
+		return 0
+	}, running).ToSlice()
+	sort.Strings(byKey)
+	if want := []string{"jerry:2", "jerry:6", "tom:1", "tom:4", "tom:9"}; !reflect.DeepEqual(byKey, want) {
+		t.Errorf("MapWithStateByKey() = %v, want %v", byKey, want)
+	}
+}
+
+func TestMapWithStateTTL(t *testing.T) {
+	out := make(chan string, 10)
+	clock := time.Unix(0, 0)
+	st := newStateStore(func(item string) string {
+		return item
+	}, func(key string) int {
+		return 0
+	}, func(count *int, item string) (string, bool) {
+		*count++
+		return "", false
+	}, newStateOptions([]StateOption[string, int, string]{
+		WithStateTTL[string, int, string](time.Minute),
+		WithOnEvict(func(key string, count int) (string, bool) {
+			return fmt.Sprintf("%s=%d", key, count), true
+		}),
+	}), out)
+	st.now = func() time.Time {
+		return clock
+	}
+	st.process("a")
+	st.process("b")
+	clock = clock.Add(30 * time.Second)
+	st.process("a")
+	clock = clock.Add(40 * time.Second)
+	st.evictExpired()
+	if got := <-out; got != "b=1" {
+		t.Errorf("first eviction = %s, want b=1", got)
+	}
+	if len(out) != 0 {
+		t.Errorf("a should not be evicted yet")
+	}
+	st.evictAll()
+	if got := <-out; got != "a=2" {
+		t.Errorf("final eviction = %s, want a=2", got)
+	}
+}
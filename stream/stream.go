@@ -15,6 +15,7 @@ type (
 	Stream[T any] struct {
 		source     <-chan T
 		isParallel bool
+		ctl        *control
 	}
 
 	Optional[T any] struct {
@@ -78,9 +79,10 @@ func (s Stream[T]) Concat(others ...Stream[T]) Stream[T] {
 		}
 		close(source)
 	}()
-	return Range(source, s.isParallel)
+	return Range(source, s.isParallel).linked(append([]*control{s.ctl}, controls(others)...)...)
 }
 func (s Stream[T]) Count() (count int64) {
+	s.checkConsumed()
 	for range s.source {
 		count++
 	}
@@ -118,17 +120,24 @@ func (s Stream[T]) Limit(maxSize int64) Stream[T] {
 	source := make(chan T)
 	go func() {
 		var n int64 = 0
-		for item := range s.source {
-			if n < maxSize {
-				source <- item
-				n++
-			} else {
+		if maxSize == 0 {
+			s.stop()
+		}
+		for n < maxSize {
+			item, ok := <-s.source
+			if !ok {
 				break
 			}
+			source <- item
+			n++
+			// 已经满足时立即停止上游，不再多读取一个元素
+			if n == maxSize {
+				s.stop()
+			}
 		}
 		close(source)
 	}()
-	return Range(source, s.isParallel)
+	return Range(source, s.isParallel).linked(s.ctl)
 }
 
 func (s Stream[T]) Skip(n int64) Stream[T] {
@@ -151,7 +160,7 @@ func (s Stream[T]) Skip(n int64) Stream[T] {
 		close(source)
 	}()
 
-	return Range(source, s.isParallel)
+	return Range(source, s.isParallel).linked(s.ctl)
 }
 
 // TakeWhile 获取满足fn 函数(从第一个开始(包括))，之前的数据
@@ -161,12 +170,13 @@ func (s Stream[T]) TakeWhile(fn func(item T) bool) Stream[T] {
 		for item := range s.source {
 			source <- item
 			if fn(item) {
+				s.stop()
 				break
 			}
 		}
 		close(source)
 	}()
-	return Range(source, s.isParallel)
+	return Range(source, s.isParallel).linked(s.ctl)
 }
 
 // DropWhile 丢弃满足fn 函数(从第一个开始截取)，之前的数据
@@ -186,7 +196,7 @@ func (s Stream[T]) DropWhile(fn func(item T) bool) Stream[T] {
 		}
 		close(source)
 	}()
-	return Range(source, s.isParallel)
+	return Range(source, s.isParallel).linked(s.ctl)
 }
 
 func (s Stream[T]) Distinct(fn func(item T) any) Stream[T] {
@@ -203,7 +213,7 @@ func (s Stream[T]) Distinct(fn func(item T) any) Stream[T] {
 			}
 		}
 	})
-	return Range(source, s.isParallel)
+	return Range(source, s.isParallel).linked(s.ctl)
 }
 
 func (s Stream[T]) Sorted(less func(a, b T) bool) Stream[T] {
@@ -214,7 +224,7 @@ func (s Stream[T]) Sorted(less func(a, b T) bool) Stream[T] {
 	sort.Slice(items, func(i, j int) bool {
 		return less(items[i], items[j])
 	})
-	return Of(items...)
+	return Of(items...).linked(s.ctl)
 }
 
 func (s Stream[T]) Reverse() Stream[T] {
@@ -227,34 +237,31 @@ func (s Stream[T]) Reverse() Stream[T] {
 		items[i], items[opp] = items[opp], items[i]
 	}
 
-	return Of(items...)
+	return Of(items...).linked(s.ctl)
 }
 
 func (s Stream[T]) Max(comparator func(T, T) int) Optional[T] {
-	max, ok := s.FindFirst().Get()
-	if !ok {
-		return Optional[T]{v: nil}
-	}
-	s.ForEach(func(t T) {
-		if comparator(t, max) > 0 {
-			max = t
+	s.checkConsumed()
+	var max *T
+	for item := range s.source {
+		if max == nil || comparator(item, *max) > 0 {
+			val := item
+			max = &val
 		}
-	})
-	return Optional[T]{v: &max}
+	}
+	return Optional[T]{v: max}
 }
 
 func (s Stream[T]) Min(comparator func(T, T) int) Optional[T] {
-
-	min, ok := s.FindFirst().Get()
-	if !ok {
-		return Optional[T]{v: nil}
-	}
-	s.ForEach(func(t T) {
-		if comparator(t, min) < 0 {
-			min = t
+	s.checkConsumed()
+	var min *T
+	for item := range s.source {
+		if min == nil || comparator(item, *min) < 0 {
+			val := item
+			min = &val
 		}
-	})
-	return Optional[T]{v: &min}
+	}
+	return Optional[T]{v: min}
 }
 
 type SumIntStatistics[T int | int32 | int64] struct {
@@ -286,6 +293,7 @@ func (s SumIntStatistics[T]) GetAverage() float64 {
 }
 
 func (s Stream[T]) SumIntStatistics() SumIntStatistics[int] {
+	s.checkConsumed()
 	var cnt = 0
 	var sum int64
 	var max int
@@ -310,6 +318,7 @@ func (s Stream[T]) SumIntStatistics() SumIntStatistics[int] {
 }
 
 func (s Stream[T]) SumInt32Statistics() SumIntStatistics[int32] {
+	s.checkConsumed()
 	var cnt = 0
 	var sum int64
 	var max int32
@@ -334,6 +343,7 @@ func (s Stream[T]) SumInt32Statistics() SumIntStatistics[int32] {
 }
 
 func (s Stream[T]) SumInt64Statistics() SumIntStatistics[int64] {
+	s.checkConsumed()
 	var cnt = 0
 	var sum int64
 	var max int64
@@ -382,6 +392,7 @@ func (s SumFloatStatistics[T]) GetAverage() float64 {
 }
 
 func (s Stream[T]) SumFloat32Statistics() SumFloatStatistics[float32] {
+	s.checkConsumed()
 	var cnt = 0
 	var sum float64
 	var max float32
@@ -406,6 +417,7 @@ func (s Stream[T]) SumFloat32Statistics() SumFloatStatistics[float32] {
 }
 
 func (s Stream[T]) SumFloat64Statistics() SumFloatStatistics[float64] {
+	s.checkConsumed()
 	var cnt = 0
 	var sum float64
 	var max float64
@@ -430,6 +442,7 @@ func (s Stream[T]) SumFloat64Statistics() SumFloatStatistics[float64] {
 }
 
 func (s Stream[T]) ForEach(fn func(item T)) {
+	s.checkConsumed()
 	var workers = 1
 	if s.isParallel {
 		workers = runtime.NumCPU() * 2
@@ -455,6 +468,17 @@ func (s Stream[T]) ForEach(fn func(item T)) {
 	close(pool)
 }
 
+// ForEachWhile 在当前协程中按顺序逐个处理元素，fn 返回 false 时停止，上游不再继续生产
+func (s Stream[T]) ForEachWhile(fn func(item T) bool) {
+	s.checkConsumed()
+	for item := range s.source {
+		if !fn(item) {
+			s.stop()
+			return
+		}
+	}
+}
+
 // Walk 让调用者处理每个Item，调用者可以根据给定的Item编写零个、一个或多个项目
 func (s Stream[T]) Walk(fn func(item T, pipe chan<- T)) Stream[T] {
 	return s.walkLimited(fn)
@@ -462,43 +486,19 @@ func (s Stream[T]) Walk(fn func(item T, pipe chan<- T)) Stream[T] {
 
 // walkLimited 遍历工作的协程个数限制
 func (s Stream[T]) walkLimited(fn func(item T, pipe chan<- T)) Stream[T] {
-	var workers = 1
-	if s.isParallel {
-		workers = runtime.NumCPU() * 2
-	}
-	pipe := make(chan T, workers)
-	go func() {
-		defer close(pipe)
-		var wg sync.WaitGroup
-		// 这里是个占位类型
-		pool := make(chan struct{}, workers)
-		for item := range s.source {
-			val := item
-			// 这里是个占位类型值
-			pool <- struct{}{}
-			wg.Add(1)
-			GoSafe(func() {
-				defer func() {
-					wg.Done()
-					<-pool
-				}()
-				fn(val, pipe)
-			})
-		}
-		wg.Wait()
-	}()
-	return Range(pipe, s.isParallel)
+	return walk(s, fn)
 }
 
 // AllMatch 返回此流中是否全都满足条件
 func (s Stream[T]) AllMatch(predicate func(T) bool) bool {
+	s.checkConsumed()
 	// 非缓冲通道
 	flag := make(chan bool)
 	GoSafe(func() {
 		tempFlag := true
 		for item := range s.source {
 			if !predicate(item) {
-				go drain(s.source)
+				s.stop()
 				tempFlag = false
 				break
 			}
@@ -510,12 +510,13 @@ func (s Stream[T]) AllMatch(predicate func(T) bool) bool {
 
 // AnyMatch 返回此流中是否存在元素满足所提供的条件
 func (s Stream[T]) AnyMatch(predicate func(T) bool) bool {
+	s.checkConsumed()
 	flag := make(chan bool)
 	GoSafe(func() {
 		tempFlag := false
 		for item := range s.source {
 			if predicate(item) {
-				go drain(s.source)
+				s.stop()
 				tempFlag = true
 				break
 			}
@@ -527,12 +528,13 @@ func (s Stream[T]) AnyMatch(predicate func(T) bool) bool {
 
 // NoneMatch 返回此流中是否全都不满足条件
 func (s Stream[T]) NoneMatch(predicate func(T) bool) bool {
+	s.checkConsumed()
 	flag := make(chan bool)
 	GoSafe(func() {
 		tempFlag := true
 		for item := range s.source {
 			if predicate(item) {
-				go drain(s.source)
+				s.stop()
 				tempFlag = false
 				break
 			}
@@ -544,14 +546,16 @@ func (s Stream[T]) NoneMatch(predicate func(T) bool) bool {
 }
 
 func (s Stream[T]) FindFirst() Optional[T] {
+	s.checkConsumed()
 	for item := range s.source {
-		go drain(s.source)
+		s.stop()
 		return Optional[T]{v: &item}
 	}
 	return Optional[T]{v: nil}
 }
 
 func (s Stream[T]) FindLast() Optional[T] {
+	s.checkConsumed()
 	tempStream := s.Reverse()
 	for item := range tempStream.source {
 		go drain(tempStream.source)
@@ -561,6 +565,7 @@ func (s Stream[T]) FindLast() Optional[T] {
 }
 
 func (s Stream[T]) Reduce(accumulator func(T, T) T) Optional[T] {
+	s.checkConsumed()
 	var cnt = 0
 	var res T
 	for item := range s.source {
@@ -579,6 +584,7 @@ func (s Stream[T]) Reduce(accumulator func(T, T) T) Optional[T] {
 }
 
 func (s Stream[T]) Joining(seq string) string {
+	s.checkConsumed()
 	// assert
 	var b strings.Builder
 	for item := range s.source {
@@ -647,6 +653,7 @@ func (s Stream[T]) FlatMapToFloat32(mapper func(T) Stream[float32]) Stream[float
 }
 
 func (s Stream[T]) ToSlice() []T {
+	s.checkConsumed()
 	r := make([]T, 0)
 	for item := range s.source {
 		r = append(r, item)
@@ -655,6 +662,7 @@ func (s Stream[T]) ToSlice() []T {
 }
 
 func (s Stream[T]) ToMapString(keyMapper func(T) string, valueMapper func(T) T, opts ...func(oldV, newV T) T) map[string]T {
+	s.checkConsumed()
 	res := make(map[string]T, 0)
 	for item := range s.source {
 		key := keyMapper(item)
@@ -680,6 +688,7 @@ func (s Stream[T]) ToMapString(keyMapper func(T) string, valueMapper func(T) T,
 }
 
 func (s Stream[T]) ToMapInt(keyMapper func(T) int, valueMapper func(T) T, opts ...func(oldV, newV T) T) map[int]T {
+	s.checkConsumed()
 	res := make(map[int]T, 0)
 	for item := range s.source {
 		key := keyMapper(item)
@@ -705,6 +714,7 @@ func (s Stream[T]) ToMapInt(keyMapper func(T) int, valueMapper func(T) T, opts .
 
 // Deprecated: This function is no longer recommended. Please use extracted.Collect() instead.
 func (s Stream[T]) Collect(collector collectors.Collector[T, T, any]) any {
+	s.checkConsumed()
 	temp := collector.Supplier()()
 	for item := range s.source {
 		collector.Accumulator()(item, temp)
@@ -749,9 +759,15 @@ func Range[T any](source <-chan T, isParallel bool) Stream[T] {
 	return Stream[T]{
 		source:     source,
 		isParallel: isParallel,
+		ctl:        newControl(),
 	}
 }
 
+// Parallel 转换为可并行执行的流，之后的 Filter、Walk、ForEach 等操作和 OfParallel 一样使用多个协程执行，不保证元素的顺序
+func (s Stream[T]) Parallel() Stream[T] {
+	return Range(s.source, true).linked(s.ctl)
+}
+
 func (s Stream[T]) GroupingByString(groupFunc func(T) string, opts ...OptFunc[T]) map[string][]T {
 	groups := make(map[string][]T)
 	s.ForEach(func(t T) {
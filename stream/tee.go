@@ -0,0 +1,133 @@
+package stream
+
+import "sync"
+
+// TeeOption 设置 Tee/Broadcast 分支的缓冲和背压策略，默认作用于所有分支，通过 WithTeeBranch 设置单个分支
+type TeeOption func(*teeOptions)
+
+type teeOptions struct {
+	bufferSize int
+	dropOnFull bool
+	branches   map[int][]TeeOption
+}
+
+// branch 返回第 i 个分支的选项：所有分支的选项加上 WithTeeBranch 为该分支设置的选项
+func (o teeOptions) branch(i int) teeOptions {
+	res := teeOptions{bufferSize: o.bufferSize, dropOnFull: o.dropOnFull}
+	for _, opt := range o.branches[i] {
+		opt(&res)
+	}
+	return res
+}
+
+// WithTeeBranch 只为第 i 个分支(从 0 开始，Broadcast 中为第 i 个消费者)设置 opts，覆盖作用于所有分支的选项
+//
+// eg:
+//
+//	// 慢的分支 1 缓冲区满时丢弃元素，分支 0 每个元素都会收到
+//	streams := s.Tee(2, WithTeeBranch(1, WithTeeBuffer(100), WithTeeDropOnFull()))
+func WithTeeBranch(i int, opts ...TeeOption) TeeOption {
+	if i < 0 {
+		panic("branch index must not be negative")
+	}
+	return func(o *teeOptions) {
+		if o.branches == nil {
+			o.branches = make(map[int][]TeeOption)
+		}
+		o.branches[i] = append(o.branches[i], opts...)
+	}
+}
+
+// WithTeeBuffer 设置分支的缓冲区大小，默认为 0，即所有分支同步消费
+func WithTeeBuffer(size int) TeeOption {
+	return func(o *teeOptions) {
+		if size < 0 {
+			panic("size must not be negative")
+		}
+		o.bufferSize = size
+	}
+}
+
+// WithTeeDropOnFull 分支缓冲区满时丢弃发往该分支的元素，慢的分支不会阻塞其他分支
+// 默认情况下缓冲区满会阻塞，最慢的分支决定了整体的速度；通常配合 WithTeeBranch 只用于允许丢弃的分支
+func WithTeeDropOnFull() TeeOption {
+	return func(o *teeOptions) {
+		o.dropOnFull = true
+	}
+}
+
+// Tee 把一个流复制成 n 个相互独立的流，每个元素都会发送给所有分支
+// 注意：n 个分支需要同时被消费(比如在不同的协程中)，否则在默认阻塞策略下未被消费的分支会阻塞其他分支
+// 某个分支提前结束(比如 Limit、FindFirst)不影响其他分支，所有分支都结束之后才停止上游
+func (s Stream[T]) Tee(n int, opts ...TeeOption) []Stream[T] {
+	if n <= 0 {
+		panic("n must be positive")
+	}
+	o := teeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for i := range o.branches {
+		if i >= n {
+			panic("branch index out of range")
+		}
+	}
+	branches := make([]chan T, n)
+	dropOnFull := make([]bool, n)
+	streams := make([]Stream[T], n)
+	shared := newSharedControl(n, s.ctl)
+	for i := range branches {
+		bo := o.branch(i)
+		branches[i] = make(chan T, bo.bufferSize)
+		dropOnFull[i] = bo.dropOnFull
+		streams[i] = Range(branches[i], s.isParallel).linked(shared)
+	}
+	GoSafe(func() {
+		defer func() {
+			for _, branch := range branches {
+				close(branch)
+			}
+		}()
+		for item := range s.source {
+			for i, branch := range branches {
+				if !dropOnFull[i] {
+					branch <- item
+					continue
+				}
+				select {
+				case branch <- item:
+				default:
+				}
+			}
+		}
+	})
+	return streams
+}
+
+// Broadcast 把流广播给多个消费者，每个消费者在独立的协程中消费自己的分支，所有消费者结束后返回
+func (s Stream[T]) Broadcast(consumers ...func(Stream[T])) {
+	s.BroadcastWith(nil, consumers...)
+}
+
+// BroadcastWith 同 Broadcast，可以通过 opts 设置每个分支的缓冲和背压策略
+func (s Stream[T]) BroadcastWith(opts []TeeOption, consumers ...func(Stream[T])) {
+	if len(consumers) == 0 {
+		drain(s.source)
+		return
+	}
+	streams := s.Tee(len(consumers), opts...)
+	var wg sync.WaitGroup
+	wg.Add(len(consumers))
+	for i, consumer := range consumers {
+		i, consumer := i, consumer
+		GoSafe(func() {
+			defer func() {
+				wg.Done()
+				// 消费者提前结束(或 panic)时继续消费剩余的元素，避免阻塞其他分支
+				drain(streams[i].source)
+			}()
+			consumer(streams[i])
+		})
+	}
+	wg.Wait()
+}
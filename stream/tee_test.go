@@ -0,0 +1,123 @@
+package stream
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTee(t *testing.T) {
+	streams := Of(1, 2, 3, 4).Tee(2)
+	var wg sync.WaitGroup
+	results := make([][]int, len(streams))
+	for i, s := range streams {
+		i, s := i, s
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.ToSlice()
+		}()
+	}
+	wg.Wait()
+	for i, res := range results {
+		if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(res, want) {
+			t.Errorf("Tee() branch %d = %v, want %v", i, res, want)
+		}
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	var sum int
+	var count int64
+	Of(1, 2, 3, 4).Broadcast(func(s Stream[int]) {
+		sum = s.Reduce(func(a, b int) int {
+			return a + b
+		}).OrElse(0)
+	}, func(s Stream[int]) {
+		count = s.Count()
+	}, func(s Stream[int]) {
+		// 提前结束的分支不会阻塞其他分支
+		s.FindFirst()
+	})
+	if sum != 10 || count != 4 {
+		t.Errorf("Broadcast() sum = %d, count = %d, want 10, 4", sum, count)
+	}
+}
+
+func TestBroadcastDropOnFull(t *testing.T) {
+	block := make(chan struct{})
+	var fast int64
+	var slow []int
+	opts := []TeeOption{WithTeeBranch(1, WithTeeBuffer(1), WithTeeDropOnFull())}
+	Of(1, 2, 3, 4, 5, 6, 7, 8).BroadcastWith(opts, func(s Stream[int]) {
+		fast = s.Count()
+		close(block)
+	}, func(s Stream[int]) {
+		<-block
+		slow = s.ToSlice()
+	})
+	// 快的分支使用默认的阻塞策略，收到所有元素；
+	// 慢的分支在源结束前一直阻塞，只能收到缓冲区中的 1 个元素，其余的都被丢弃
+	if fast != 8 || !reflect.DeepEqual(slow, []int{1}) {
+		t.Errorf("BroadcastWith() fast = %d, slow = %v, want 8, [1]", fast, slow)
+	}
+}
+
+func TestTeeBranchOptions(t *testing.T) {
+	o := teeOptions{}
+	for _, opt := range []TeeOption{WithTeeBuffer(2), WithTeeBranch(1, WithTeeBuffer(5), WithTeeDropOnFull())} {
+		opt(&o)
+	}
+	if b := o.branch(0); b.bufferSize != 2 || b.dropOnFull {
+		t.Errorf("branch 0 = %+v, want buffer 2 without drop", b)
+	}
+	if b := o.branch(1); b.bufferSize != 5 || !b.dropOnFull {
+		t.Errorf("branch 1 = %+v, want buffer 5 with drop", b)
+	}
+}
+
+func TestTeeShortCircuitBranch(t *testing.T) {
+	streams := slowSource(50).Tee(2)
+	var wg sync.WaitGroup
+	var short, full []int
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		short = streams[0].Limit(2).ToSlice()
+	}()
+	go func() {
+		defer wg.Done()
+		full = streams[1].ToSlice()
+	}()
+	wg.Wait()
+	if len(short) != 2 || len(full) != 50 {
+		t.Fatalf("short = %v, full got %d items, want 2 and 50", short, len(full))
+	}
+}
+
+func TestTeeAllBranchesStop(t *testing.T) {
+	stopped := make(chan struct{})
+	streams := OfFromE(func(emit func(int) bool) error {
+		defer close(stopped)
+		for i := 0; emit(i); i++ {
+		}
+		return nil
+	}).Tee(2)
+	var wg sync.WaitGroup
+	for _, s := range streams {
+		s := s
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Limit(3).ToSlice()
+		}()
+	}
+	wg.Wait()
+	// 所有分支都结束之后上游被取消，无限流不会一直生产
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("source was not cancelled after every branch stopped")
+	}
+}
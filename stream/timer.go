@@ -0,0 +1,155 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout Timeout 和 TimeoutEach 超时的错误
+var ErrTimeout = errors.New("stream: timeout")
+
+// EndOnTimeout Timeout 超时时只结束流，不产生错误
+func EndOnTimeout() TimeOption {
+	return func(o *timeOptions) {
+		o.endOnTimeout = true
+	}
+}
+
+// Interval 每隔 d 输出一次当前时间的无限流，需要配合 Limit、TakeWhile 等短路操作使用，
+// 下游结束(比如 Limit 已经满足)后停止
+func Interval(d time.Duration, opts ...TimeOption) Stream[time.Time] {
+	return IntervalContext(context.Background(), d, opts...)
+}
+
+// IntervalContext 同 Interval，ctx 被取消时结束
+func IntervalContext(ctx context.Context, d time.Duration, opts ...TimeOption) Stream[time.Time] {
+	if d <= 0 {
+		panic("d must be positive")
+	}
+	clock := newTimeOptions(opts).clock
+	source := make(chan time.Time)
+	res := Range(source, false)
+	GoSafe(func() {
+		defer close(source)
+		for {
+			var now time.Time
+			select {
+			case now = <-clock.After(d):
+			case <-ctx.Done():
+				return
+			case <-res.ctl.Done():
+				return
+			}
+			select {
+			case source <- now:
+			case <-ctx.Done():
+				return
+			case <-res.ctl.Done():
+				return
+			}
+		}
+	})
+	return res
+}
+
+// Timer 等待 d 之后输出一次当前时间
+func Timer(d time.Duration, opts ...TimeOption) Stream[time.Time] {
+	clock := newTimeOptions(opts).clock
+	source := make(chan time.Time, 1)
+	GoSafe(func() {
+		defer close(source)
+		source <- <-clock.After(d)
+	})
+	return Range(source, false)
+}
+
+// Delay 每个元素延迟 d 之后输出，元素之间的时间间隔保持不变
+func (s Stream[T]) Delay(d time.Duration, opts ...TimeOption) Stream[T] {
+	clock := newTimeOptions(opts).clock
+	type delayed struct {
+		item T
+		at   time.Time
+	}
+	// 记录每个元素到达的时间，输出时等到 到达时间+d
+	pending := make(chan delayed, 64)
+	GoSafe(func() {
+		defer close(pending)
+		for item := range s.source {
+			pending <- delayed{item: item, at: clock.Now().Add(d)}
+		}
+	})
+	source := make(chan T)
+	GoSafe(func() {
+		defer close(source)
+		for p := range pending {
+			if wait := p.at.Sub(clock.Now()); wait > 0 {
+				<-clock.After(wait)
+			}
+			source <- p.item
+		}
+	})
+	return Range(source, s.isParallel).linked(s.ctl)
+}
+
+// Timeout 从开始或者上一个元素到达起，超过 d 没有新的元素到达时结束流，
+// Err() 返回 ErrTimeout，设置 EndOnTimeout() 时只结束流，不产生错误
+func (s Stream[T]) Timeout(d time.Duration, opts ...TimeOption) Stream[T] {
+	o := newTimeOptions(opts)
+	source := make(chan T)
+	res := Range(source, s.isParallel).linked(s.ctl)
+	GoSafe(func() {
+		defer close(source)
+		timer := o.clock.NewTimer(d)
+		defer timer.Stop()
+		for {
+			select {
+			case item, ok := <-s.source:
+				if !ok {
+					return
+				}
+				// 等待下游接收的时间不计入超时
+				timer.Stop()
+				source <- item
+				timer.Reset(d)
+			case <-timer.C():
+				if !o.endOnTimeout {
+					res.ctl.fail(ErrTimeout)
+				}
+				s.stop()
+				return
+			}
+		}
+	})
+	return res
+}
+
+/*
+TimeoutEach 对每个元素调用 fn 进行转换，每次调用最多 d 时间
+超时后传给 fn 的 ctx 被取消，该元素被丢弃，Err() 返回 ErrTimeout；fn 返回的错误同样会丢弃元素并记录到 Err() 中
+*/
+func TimeoutEach[T any, R any](s Stream[T], d time.Duration, fn func(ctx context.Context, item T) (R, error), opts ...TimeOption) Stream[R] {
+	clock := newTimeOptions(opts).clock
+	ctl := newControl()
+	return walk(s, func(item T, pipe chan<- R) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		timer := clock.NewTimer(d)
+		defer timer.Stop()
+		done := make(chan concurrentResult[R], 1)
+		go func() {
+			r, err := safeCall(ctx, item, fn)
+			done <- concurrentResult[R]{value: r, err: err}
+		}()
+		select {
+		case r := <-done:
+			if r.err != nil {
+				ctl.fail(r.err)
+				return
+			}
+			pipe <- r.value
+		case <-timer.C():
+			ctl.fail(ErrTimeout)
+		}
+	}).linked(ctl)
+}
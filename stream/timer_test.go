@@ -0,0 +1,135 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterval(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	s := Interval(time.Second, WithClock(clock))
+	for i := 1; i <= 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		if got, want := <-s.source, start.Add(time.Duration(i)*time.Second); !got.Equal(want) {
+			t.Fatalf("tick %d: got %v, want %v", i, got, want)
+		}
+	}
+	// 下游结束后停止
+	s.ctl.cancel()
+	if _, ok := <-s.source; ok {
+		t.Fatal("stream should be closed")
+	}
+}
+
+func TestIntervalContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := NewFakeClock(time.Unix(0, 0))
+	s := IntervalContext(ctx, time.Second, WithClock(clock))
+	clock.BlockUntil(1)
+	cancel()
+	if _, ok := <-s.source; ok {
+		t.Fatal("stream should be closed")
+	}
+}
+
+func TestTimer(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	s := Timer(time.Minute, WithClock(clock))
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if got := s.ToSlice(); len(got) != 1 || !got[0].Equal(time.Unix(60, 0)) {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	out := Of(1, 2).Delay(time.Second, WithClock(clock)).source
+	clock.BlockUntil(1)
+	select {
+	case <-out:
+		t.Fatal("element emitted before delay")
+	default:
+	}
+	clock.Advance(time.Second)
+	// 两个元素同时到达，同时到期
+	if a, b := <-out, <-out; a != 1 || b != 2 {
+		t.Fatalf("got %d %d, want 1 2", a, b)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("stream should be closed")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	for _, end := range []bool{false, true} {
+		clock := NewFakeClock(time.Unix(0, 0))
+		ch := make(chan int)
+		opts := []TimeOption{WithClock(clock)}
+		if end {
+			opts = append(opts, EndOnTimeout())
+		}
+		s := Range(ch, false).Timeout(time.Second, opts...)
+		go func() {
+			ch <- 1
+			ch <- 2
+		}()
+		if a, b := <-s.source, <-s.source; a != 1 || b != 2 {
+			t.Fatalf("got %d %d, want 1 2", a, b)
+		}
+		// 发送给下游时定时器停止，下游接收之后重置同一个定时器，不会留下还在等待的定时器
+		clock.BlockUntil(1)
+		if n := clock.waiting(); n != 1 {
+			t.Fatalf("%d timers pending, want 1", n)
+		}
+		clock.Advance(time.Second)
+		if _, ok := <-s.source; ok {
+			t.Fatal("stream should be closed")
+		}
+		if n := clock.waiting(); n != 0 {
+			t.Fatalf("%d timers pending after timeout, want 0", n)
+		}
+		var want error = ErrTimeout
+		if end {
+			want = nil
+		}
+		if err := s.Err(); err != want {
+			t.Fatalf("err = %v, want %v", err, want)
+		}
+		close(ch)
+	}
+}
+
+func TestTimeoutEach(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	started := make(chan struct{})
+	s := TimeoutEach(Of(1, 2, 3), time.Second, func(ctx context.Context, item int) (int, error) {
+		if item == 2 {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return item * 10, nil
+	}, WithClock(clock))
+	done := make(chan []int)
+	go func() {
+		done <- s.ToSlice()
+	}()
+	<-started
+	// 第一个元素的定时器已经停止，只有第二个元素在等待
+	if n := clock.waiting(); n != 1 {
+		t.Fatalf("%d timers pending, want 1", n)
+	}
+	clock.Advance(time.Second)
+	if got := <-done; !reflect.DeepEqual(got, []int{10, 30}) {
+		t.Fatalf("got %v, want [10 30]", got)
+	}
+	if err := s.Err(); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+}
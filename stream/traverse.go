@@ -0,0 +1,322 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCycle TopologicalSort 的图中存在环
+var ErrCycle = errors.New("stream: graph has a cycle")
+
+// TraverseOption 设置 TraverseBFS、TraverseDFS、FlatMapDeep 的选项
+type TraverseOption func(*traverseOptions)
+
+type traverseOptions struct {
+	key       func(item any) any
+	maxDepth  int
+	postOrder bool
+}
+
+func newTraverseOptions(opts []TraverseOption) traverseOptions {
+	o := traverseOptions{maxDepth: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithVisitedKey 记录已经访问过的节点的 key，key 相同的节点只访问一次，用于存在环或者共享子节点的图
+// 默认不去重，遍历有环的图时会无限输出；T 必须和流中元素的类型相同
+func WithVisitedKey[T any, K comparable](key func(item T) K) TraverseOption {
+	return func(o *traverseOptions) {
+		o.key = func(item any) any {
+			return key(item.(T))
+		}
+	}
+}
+
+// MaxDepth 最多遍历到根节点下第 n 层，根节点的深度为 0，默认不限制
+func MaxDepth(n int) TraverseOption {
+	return func(o *traverseOptions) {
+		o.maxDepth = n
+	}
+}
+
+// PostOrder TraverseDFS、FlatMapDeep 按后序输出(先输出所有子节点，再输出节点本身)，默认为前序
+func PostOrder() TraverseOption {
+	return func(o *traverseOptions) {
+		o.postOrder = true
+	}
+}
+
+// traverser 遍历的状态，visited 在 FlatMapDeep 的所有根节点之间共享
+type traverser[T any] struct {
+	o        traverseOptions
+	children func(T) []T
+	visited  map[any]struct{}
+	emit     func(T) bool
+}
+
+func newTraverser[T any](children func(T) []T, opts []TraverseOption, emit func(T) bool) *traverser[T] {
+	return &traverser[T]{o: newTraverseOptions(opts), children: children, visited: make(map[any]struct{}), emit: emit}
+}
+
+// visit 标记 item 已经访问，返回 false 表示之前已经访问过
+func (t *traverser[T]) visit(item T) bool {
+	if t.o.key == nil {
+		return true
+	}
+	key := t.o.key(item)
+	if _, ok := t.visited[key]; ok {
+		return false
+	}
+	t.visited[key] = struct{}{}
+	return true
+}
+
+func (t *traverser[T]) seen(item T) bool {
+	if t.o.key == nil {
+		return false
+	}
+	_, ok := t.visited[t.o.key(item)]
+	return ok
+}
+
+// expand 深度为 depth 的节点是否需要继续遍历子节点
+func (t *traverser[T]) expand(depth int) bool {
+	return t.o.maxDepth < 0 || depth < t.o.maxDepth
+}
+
+type traverseNode[T any] struct {
+	item  T
+	depth int
+}
+
+// bfs 按层遍历 root，返回 false 表示下游已经结束
+func (t *traverser[T]) bfs(root T) bool {
+	if !t.visit(root) {
+		return true
+	}
+	queue := []traverseNode[T]{{item: root}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if !t.emit(n.item) {
+			return false
+		}
+		if !t.expand(n.depth) {
+			continue
+		}
+		for _, c := range t.children(n.item) {
+			if t.visit(c) {
+				queue = append(queue, traverseNode[T]{item: c, depth: n.depth + 1})
+			}
+		}
+	}
+	return true
+}
+
+// preOrder 通过显式的栈前序遍历 root，很深的树也不会栈溢出
+func (t *traverser[T]) preOrder(root T) bool {
+	stack := []traverseNode[T]{{item: root}}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !t.visit(n.item) {
+			continue
+		}
+		if !t.emit(n.item) {
+			return false
+		}
+		if !t.expand(n.depth) {
+			continue
+		}
+		// 逆序入栈，保证第一个子节点最先输出
+		children := t.children(n.item)
+		for i := len(children) - 1; i >= 0; i-- {
+			if !t.seen(children[i]) {
+				stack = append(stack, traverseNode[T]{item: children[i], depth: n.depth + 1})
+			}
+		}
+	}
+	return true
+}
+
+// postOrder 后序遍历 root，节点的所有子节点输出之后再输出节点本身
+func (t *traverser[T]) postOrder(root T) bool {
+	type frame struct {
+		traverseNode[T]
+		children []T
+		next     int
+	}
+	enter := func(item T, depth int) frame {
+		f := frame{traverseNode: traverseNode[T]{item: item, depth: depth}}
+		if t.expand(depth) {
+			f.children = t.children(item)
+		}
+		return f
+	}
+	if !t.visit(root) {
+		return true
+	}
+	stack := []frame{enter(root, 0)}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < len(top.children) {
+			c := top.children[top.next]
+			top.next++
+			if t.visit(c) {
+				stack = append(stack, enter(c, top.depth+1))
+			}
+			continue
+		}
+		item := top.item
+		stack = stack[:len(stack)-1]
+		if !t.emit(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *traverser[T]) dfs(root T) bool {
+	if t.o.postOrder {
+		return t.postOrder(root)
+	}
+	return t.preOrder(root)
+}
+
+/*
+TraverseBFS 从 root 开始按层(广度优先)遍历树或者图，children 返回节点的子节点，惰性调用
+图中存在环或者共享的子节点时需要设置 WithVisitedKey
+
+eg:
+
+	// 第二层以内的所有分类
+	categories := TraverseBFS(root, func(c *Category) []*Category {
+		return c.Children
+	}, MaxDepth(2)).ToSlice()
+*/
+func TraverseBFS[T any](root T, children func(item T) []T, opts ...TraverseOption) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		newTraverser(children, opts, emit).bfs(root)
+	})
+}
+
+/*
+TraverseDFS 从 root 开始深度优先遍历树或者图，默认前序输出，设置 PostOrder() 时后序输出，其他同 TraverseBFS
+
+eg:
+
+	// 按依赖关系先输出依赖的模块
+	modules := TraverseDFS(app, func(m Module) []Module {
+		return m.Deps
+	}, WithVisitedKey(func(m Module) string { return m.Name }), PostOrder()).ToSlice()
+*/
+func TraverseDFS[T any](root T, children func(item T) []T, opts ...TraverseOption) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		newTraverser(children, opts, emit).dfs(root)
+	})
+}
+
+/*
+FlatMapDeep 把流中的每个元素和它所有的子孙节点(通过 children 递归获取)展开为一个流，展开的顺序同 TraverseDFS
+设置 WithVisitedKey 时在整个流中去重
+
+eg:
+
+	// [1 [2 [3]] 4] => 1 2 3 4
+	res := FlatMapDeep(Of(tree...), func(n Node) []Node {
+		return n.Children
+	}).ToSlice()
+*/
+func FlatMapDeep[T any](s Stream[T], children func(item T) []T, opts ...TraverseOption) Stream[T] {
+	return generate(func(emit func(T) bool) {
+		t := newTraverser(children, opts, emit)
+		for item := range s.source {
+			if !t.dfs(item) {
+				s.stop()
+				return
+			}
+		}
+	}).linked(s.ctl)
+}
+
+/*
+TopologicalSort 按拓扑顺序输出 nodes，edges[u] 中的每个节点 v 都在 u 之后输出(u -> v)
+没有先后关系的节点保持在 nodes 中的顺序，不在 nodes 中但是可以通过 edges 从 nodes 到达的节点也会输出
+图中存在环时不输出任何节点，Err() 返回包含 ErrCycle 的错误，可以通过 errors.Is 判断
+
+eg:
+
+	// 先构建被依赖的模块: a -> b 表示 a 需要在 b 之前构建
+	order, err := TopologicalSort([]string{"app", "db", "log"}, map[string][]string{
+		"db":  {"app"},
+		"log": {"db", "app"},
+	}).ToSliceE()
+	// [log db app]
+*/
+func TopologicalSort[T comparable](nodes []T, edges map[T][]T) Stream[T] {
+	return OfFromE(func(emit func(T) bool) error {
+		order, err := topologicalOrder(nodes, edges)
+		if err != nil {
+			return err
+		}
+		for _, n := range order {
+			if !emit(n) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// topologicalOrder Kahn 算法，入度为 0 的节点按首次出现的顺序输出
+func topologicalOrder[T comparable](nodes []T, edges map[T][]T) ([]T, error) {
+	var all []T
+	inDegree := make(map[T]int)
+	add := func(n T) {
+		if _, ok := inDegree[n]; !ok {
+			inDegree[n] = 0
+			all = append(all, n)
+		}
+	}
+	for _, n := range nodes {
+		add(n)
+	}
+	// all 在遍历的过程中会追加不在 nodes 中的节点
+	for i := 0; i < len(all); i++ {
+		for _, v := range edges[all[i]] {
+			add(v)
+			inDegree[v]++
+		}
+	}
+
+	order := make([]T, 0, len(all))
+	var queue []T
+	for _, n := range all {
+		if inDegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		order = append(order, n)
+		for _, v := range edges[n] {
+			if inDegree[v]--; inDegree[v] == 0 {
+				queue = append(queue, v)
+			}
+		}
+	}
+	if len(order) < len(all) {
+		var cycle []T
+		for _, n := range all {
+			if inDegree[n] > 0 {
+				cycle = append(cycle, n)
+			}
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCycle, cycle)
+	}
+	return order, nil
+}
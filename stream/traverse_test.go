@@ -0,0 +1,127 @@
+package stream
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// tree 1 -> 2 3, 2 -> 4 5, 3 -> 6
+var tree = map[int][]int{1: {2, 3}, 2: {4, 5}, 3: {6}}
+
+func treeChildren(n int) []int {
+	return tree[n]
+}
+
+func TestTraverseBFS(t *testing.T) {
+	res := TraverseBFS(1, treeChildren).ToSlice()
+	if want := []int{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	res = TraverseBFS(1, treeChildren, MaxDepth(1)).ToSlice()
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestTraverseDFS(t *testing.T) {
+	res := TraverseDFS(1, treeChildren).ToSlice()
+	if want := []int{1, 2, 4, 5, 3, 6}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("pre-order got %v, want %v", res, want)
+	}
+	res = TraverseDFS(1, treeChildren, PostOrder()).ToSlice()
+	if want := []int{4, 5, 2, 6, 3, 1}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("post-order got %v, want %v", res, want)
+	}
+	res = TraverseDFS(1, treeChildren, PostOrder(), MaxDepth(1)).ToSlice()
+	if want := []int{2, 3, 1}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("post-order with depth got %v, want %v", res, want)
+	}
+}
+
+func TestTraverseCycle(t *testing.T) {
+	// a -> b -> c -> a, a -> c
+	graph := map[string][]string{"a": {"b", "c"}, "b": {"c"}, "c": {"a"}}
+	children := func(n string) []string { return graph[n] }
+	key := WithVisitedKey(func(n string) string { return n })
+
+	if res := TraverseBFS("a", children, key).ToSlice(); !reflect.DeepEqual(res, []string{"a", "b", "c"}) {
+		t.Fatalf("bfs got %v", res)
+	}
+	if res := TraverseDFS("a", children, key).ToSlice(); !reflect.DeepEqual(res, []string{"a", "b", "c"}) {
+		t.Fatalf("dfs got %v", res)
+	}
+	if res := TraverseDFS("a", children, key, PostOrder()).ToSlice(); !reflect.DeepEqual(res, []string{"c", "b", "a"}) {
+		t.Fatalf("post-order got %v", res)
+	}
+	// 不设置 WithVisitedKey 时无限遍历，Limit 之后结束
+	if n := TraverseDFS("a", children).Limit(10).Count(); n != 10 {
+		t.Fatalf("count = %d, want 10", n)
+	}
+}
+
+func TestTraverseStopsEarly(t *testing.T) {
+	before := runtime.NumGoroutine()
+	res := TraverseBFS(0, func(n int) []int {
+		return []int{2*n + 1, 2*n + 2}
+	}).Limit(3).ToSlice()
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("goroutines leaked: %d > %d", n, before)
+	}
+}
+
+func TestFlatMapDeep(t *testing.T) {
+	res := FlatMapDeep(Of(1, 7), treeChildren).ToSlice()
+	if want := []int{1, 2, 4, 5, 3, 6, 7}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	// 3 已经作为 1 的子节点输出过
+	res = FlatMapDeep(Of(1, 3), treeChildren, WithVisitedKey(func(n int) int { return n })).ToSlice()
+	if want := []int{1, 2, 4, 5, 3, 6}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	if res = FlatMapDeep(Of(1, 1), treeChildren).Limit(2).ToSlice(); !reflect.DeepEqual(res, []int{1, 2}) {
+		t.Fatalf("got %v", res)
+	}
+}
+
+func TestTopologicalSort(t *testing.T) {
+	res, err := TopologicalSort([]string{"app", "db", "log"}, map[string][]string{
+		"db":  {"app"},
+		"log": {"db", "app"},
+	}).ToSliceE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"log", "db", "app"}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+
+	// 没有先后关系的节点保持原来的顺序，cli 不在 nodes 中
+	res = TopologicalSort([]string{"c", "b", "a"}, map[string][]string{"b": {"cli"}}).ToSlice()
+	if want := []string{"c", "b", "a", "cli"}; !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	res, err := TopologicalSort([]int{1, 2, 3, 4}, map[int][]int{1: {2}, 2: {3}, 3: {2}}).ToSliceE()
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("err = %v, want ErrCycle", err)
+	}
+	if len(res) != 0 {
+		t.Fatalf("got %v, want no nodes", res)
+	}
+	if want := "stream: graph has a cycle: [2 3]"; err.Error() != want {
+		t.Fatalf("err = %q, want %q", err, want)
+	}
+}